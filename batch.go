@@ -0,0 +1,48 @@
+package log
+
+import (
+	"context"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Entry is one of the entries logged together by Infos.
+type Entry struct {
+	Msg    string
+	Fields []Field
+}
+
+// Infos logs entries at info level, e.g. when draining a queue. The level is
+// checked once and the trace fields are taken from ctx once and added to
+// every entry, which makes it cheaper than calling Info in a loop.
+func (l *Logger) Infos(ctx context.Context, entries []Entry) {
+	l.logBatch(nonNil(ctx), zapcore.InfoLevel, entries)
+}
+
+// logBatch is the logw of Infos. Like logw, it must be called directly by the
+// exported method so that the caller skip holds.
+func (l *Logger) logBatch(ctx context.Context, lvl zapcore.Level, entries []Entry) {
+	if len(entries) == 0 || !l.base.Core().Enabled(lvl) {
+		return
+	}
+	trace := l.traceFields(ctx, lvl)
+	reuse := l.reusesFields()
+	buf := getFields()
+	for _, e := range entries {
+		ce := l.base.Check(lvl, e.Msg)
+		if ce == nil {
+			continue
+		}
+		l.addStack(ce)
+		if !reuse {
+			*buf = nil
+		}
+		*buf = appendContextFields(ctx, append(append((*buf)[:0], e.Fields...), trace...))
+		fields := l.limit(l.opts.transform(*buf))
+		l.addSpanEvent(ctx, lvl, e.Msg, fields)
+		ce.Write(fields...)
+	}
+	if reuse {
+		putFields(buf)
+	}
+}
@@ -0,0 +1,78 @@
+package log
+
+import (
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestInfos(t *testing.T) {
+	logger, buf := newBufferLogger()
+	ctx := WithFields(spanContext(true), String(`tenant`, `acme`))
+	_, _, line, _ := runtime.Caller(0)
+	logger.Infos(ctx, []Entry{
+		{Msg: `first`, Fields: []Field{Int(`n`, 1)}},
+		{Msg: `second`},
+		{Msg: `third`, Fields: []Field{Int(`n`, 3), String(`k`, `v`)}},
+	})
+	logger.Infos(ctx, nil)
+	logger.SetLevel(WarnLevel)
+	logger.Infos(ctx, []Entry{{Msg: `hidden`}})
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	for i, want := range []struct {
+		msg string
+		n   interface{}
+	}{
+		{`first`, 1.0},
+		{`second`, nil},
+		{`third`, 3.0},
+	} {
+		e := entries[i]
+		if e[`message`] != want.msg || e[`n`] != want.n || e[`level`] != `info` {
+			t.Errorf("got %v, want %s with n=%v", e, want.msg, want.n)
+		}
+		if e[`traceId`] != testTraceID || e[`spanId`] != testSpanID || e[`tenant`] != `acme` {
+			t.Errorf("got %v, want the shared trace and context fields", e)
+		}
+		if caller, _ := e[`caller`].(string); !strings.HasSuffix(caller, `batch_test.go:`+strconv.Itoa(line+1)) {
+			t.Errorf("got caller %s, want line %d", caller, line+1)
+		}
+	}
+	if entries[0][`k`] != nil || entries[2][`k`] != `v` {
+		t.Errorf("got %v, want the fields of each entry kept apart", entries)
+	}
+}
+
+func BenchmarkInfos(b *testing.B) {
+	entries := make([]Entry, 16)
+	for i := range entries {
+		entries[i] = Entry{Msg: `drained`, Fields: []Field{Int(`n`, i), String(`queue`, `jobs`)}}
+	}
+	ctx := spanContext(true)
+	for _, bm := range []struct {
+		name string
+		log  func(*Logger)
+	}{
+		{`Infos`, func(l *Logger) { l.Infos(ctx, entries) }},
+		{`Info loop`, func(l *Logger) {
+			for _, e := range entries {
+				l.Info(ctx, e.Msg, e.Fields[0], e.Fields[1])
+			}
+		}},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			logger := NewLogger(WithWriter(io.Discard))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				bm.log(logger)
+			}
+		})
+	}
+}
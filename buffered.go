@@ -0,0 +1,36 @@
+package log
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+type bufferConfig struct {
+	flushInterval time.Duration
+	size          int
+}
+
+// WithBufferedWriter batches writes to the output in a buffer of bufSize
+// bytes, flushed when it's full and every flushInterval, to save a write
+// syscall per entry in high-throughput services. Zero values use zap's
+// defaults of 256 kB and 30 seconds. Entries still in the buffer are lost if
+// the process exits without calling Sync or Close, so combine it with
+// FlushOnSignal or a deferred Close. Syslog output isn't buffered, since
+// every entry has to be sent as its own message.
+func WithBufferedWriter(flushInterval time.Duration, bufSize int) Option {
+	return func(o *options) {
+		o.buffer = &bufferConfig{flushInterval: flushInterval, size: bufSize}
+	}
+}
+
+// buffered wraps ws in a buffer, unless it mustn't be buffered.
+func (cfg *bufferConfig) buffered(ws zapcore.WriteSyncer) zapcore.WriteSyncer {
+	if cfg == nil || ws == nil {
+		return ws
+	}
+	if _, ok := ws.(*syslogWriter); ok {
+		return ws
+	}
+	return &zapcore.BufferedWriteSyncer{WS: ws, Size: cfg.size, FlushInterval: cfg.flushInterval}
+}
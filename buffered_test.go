@@ -0,0 +1,77 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestBufferedWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewLogger(WithWriter(buf), WithBufferedWriter(time.Hour, 0))
+	defer logger.Close()
+
+	for i := 0; i < 100; i++ {
+		logger.Infow(`buffered`, `i`, i)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("got %d bytes before Sync, want none", buf.Len())
+	}
+	if err := logger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	entries := decodeLines(t, buf)
+	if len(entries) != 100 {
+		t.Fatalf("got %d entries after Sync, want 100", len(entries))
+	}
+	for i, e := range entries {
+		if e[`i`] != float64(i) {
+			t.Errorf("got %v, want entry %d", e, i)
+		}
+	}
+}
+
+func TestBufferedWriterFull(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewLogger(WithWriter(buf), WithBufferedWriter(time.Hour, 1024))
+	defer logger.Close()
+
+	for i := 0; i < 100; i++ {
+		logger.Infow(`buffered`, `i`, i)
+	}
+	// A full buffer is flushed right away.
+	if buf.Len() == 0 {
+		t.Error("got nothing written with the buffer full")
+	}
+	if err := logger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(decodeLines(t, buf)); n != 100 {
+		t.Errorf("got %d entries after Sync, want 100", n)
+	}
+}
+
+// chanWriter sends every write on a channel.
+type chanWriter chan []byte
+
+func (w chanWriter) Write(p []byte) (int, error) {
+	w <- append([]byte(nil), p...)
+	return len(p), nil
+}
+
+func TestBufferedWriterInterval(t *testing.T) {
+	w := make(chanWriter, 1)
+	logger := NewLogger(WithWriter(w), WithBufferedWriter(10*time.Millisecond, 0))
+	defer logger.Close()
+
+	logger.Infow(`first`)
+	logger.Infow(`second`)
+	select {
+	case p := <-w:
+		if entries := decodeLines(t, bytes.NewBuffer(p)); len(entries) != 2 {
+			t.Errorf("got %q flushed, want both entries in one write", p)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the flush")
+	}
+}
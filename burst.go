@@ -0,0 +1,58 @@
+package log
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// WithStartupBurst disables sampling for d after the logger is built, so
+// startup is logged in full, and enables the configured sampler afterwards.
+// It has no effect unless sampling is enabled.
+func WithStartupBurst(d time.Duration) Option {
+	return func(o *options) {
+		o.startupBurst = d
+	}
+}
+
+// WithClock sets the clock used to timestamp entries and to measure
+// intervals such as the startup burst. It defaults to the system clock.
+func WithClock(clock zapcore.Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// burstCore writes through full until the deadline and through sampled
+// afterwards. The entry time decides, so the logger's clock applies.
+type burstCore struct {
+	full, sampled zapcore.Core
+	until         time.Time
+}
+
+func newBurstCore(full, sampled zapcore.Core, until time.Time) zapcore.Core {
+	return &burstCore{full: full, sampled: sampled, until: until}
+}
+
+func (c *burstCore) Enabled(level Level) bool {
+	return c.full.Enabled(level)
+}
+
+func (c *burstCore) With(fields []Field) zapcore.Core {
+	return &burstCore{full: c.full.With(fields), sampled: c.sampled.With(fields), until: c.until}
+}
+
+func (c *burstCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Time.Before(c.until) {
+		return c.full.Check(ent, ce)
+	}
+	return c.sampled.Check(ent, ce)
+}
+
+func (c *burstCore) Write(ent zapcore.Entry, fields []Field) error {
+	return c.full.Write(ent, fields)
+}
+
+func (c *burstCore) Sync() error {
+	return c.full.Sync()
+}
@@ -0,0 +1,30 @@
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStartupBurst(t *testing.T) {
+	clock := newTestClock()
+	logger, buf := newBufferLogger(WithClock(clock), WithSampling(1, 1000), WithStartupBurst(10*time.Second))
+
+	log := func(n int) int {
+		buf.Reset()
+		for i := 0; i < n; i++ {
+			logger.Infow(`hot path`)
+		}
+		return len(decodeLines(t, buf))
+	}
+	if got := log(5); got != 5 {
+		t.Errorf("got %d entries during the burst, want 5", got)
+	}
+	clock.Add(10*time.Second - time.Millisecond)
+	if got := log(5); got != 5 {
+		t.Errorf("got %d entries at the end of the burst, want 5", got)
+	}
+	clock.Add(time.Millisecond)
+	if got := log(5); got != 1 {
+		t.Errorf("got %d entries after the burst, want 1", got)
+	}
+}
@@ -0,0 +1,56 @@
+package log
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const canceledMsg = `context canceled`
+
+// WithCancellationLogging makes CmdWriters and Middleware log a single
+// "context canceled" warning, with the elapsed time and the trace fields, when
+// the context of a command or request is canceled before it completes. This
+// tells client disconnects apart from server errors. The warning has no
+// caller, since it's logged by a goroutine of the logger rather than by the
+// code that was canceled.
+func WithCancellationLogging() Option {
+	return func(o *options) {
+		o.cancellationLogging = true
+	}
+}
+
+// watchCancel logs a warning if ctx is done before stop is called. stop may
+// be called more than once.
+func (l *Logger) watchCancel(ctx context.Context) (stop func()) {
+	if !l.opts.cancellationLogging || ctx == nil || ctx.Done() == nil {
+		return func() {}
+	}
+	start := l.opts.clock.Now()
+	done := make(chan struct{})
+	// The caller would point into this goroutine, which tells nothing.
+	nc := l.WithOptions(zap.WithCaller(false))
+	go func() {
+		select {
+		case <-ctx.Done():
+			// Both channels are ready if the goroutine only starts after
+			// ctx was canceled following stop.
+			select {
+			case <-done:
+				return
+			default:
+			}
+			nc.logw(ctx, zapcore.WarnLevel, canceledMsg, []interface{}{
+				Duration(`elapsed`, l.opts.clock.Now().Sub(start)),
+				String(`reason`, ctx.Err().Error()),
+			})
+		case <-done:
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
@@ -0,0 +1,123 @@
+package log
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// canceledHook returns a hook option and a channel receiving a value once the
+// cancellation warning has been written.
+func canceledHook() (Option, <-chan struct{}) {
+	canceled := make(chan struct{}, 1)
+	return WithHook(func(ent zapcore.Entry) error {
+		if ent.Message == canceledMsg {
+			canceled <- struct{}{}
+		}
+		return nil
+	}), canceled
+}
+
+func waitCanceled(t *testing.T, canceled <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the cancellation warning")
+	}
+}
+
+func TestCancellationLoggingMiddleware(t *testing.T) {
+	hook, canceled := canceledHook()
+	logger, buf := newBufferLogger(WithCancellationLogging(), hook)
+	ctx, cancel := context.WithCancel(spanContext(true))
+	defer cancel()
+	handler := Middleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The client disconnects mid-request.
+		cancel()
+		waitCanceled(t, canceled)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, `/slow`, nil).WithContext(ctx))
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want the warning and the request", len(entries))
+	}
+	e := entries[0]
+	if e[`level`] != `warn` || e[`message`] != canceledMsg || e[`reason`] != context.Canceled.Error() || e[`traceId`] != testTraceID {
+		t.Errorf("got %v, want a cancellation warning with the reason and the trace ID", e)
+	}
+	if _, ok := e[`elapsed`].(float64); !ok {
+		t.Errorf("got %v, want the elapsed time", e)
+	}
+	if caller, ok := e[`caller`]; ok {
+		t.Errorf("got caller %v, want none", caller)
+	}
+	if entries[1][`message`] != requestMsg {
+		t.Errorf("got %v, want the request entry", entries[1])
+	}
+}
+
+func TestCancellationLoggingCmdWriters(t *testing.T) {
+	hook, canceled := canceledHook()
+	logger, buf := newBufferLogger(WithCancellationLogging(), hook)
+	ctx, cancel := context.WithCancel(context.Background())
+	stdout, stderr := logger.CmdWriters(ctx)
+	cancel()
+	waitCanceled(t, canceled)
+	stdout.Close()
+	stderr.Close()
+	if e := decodeLine(t, buf); e[`message`] != canceledMsg {
+		t.Errorf("got %v, want the cancellation warning", e)
+	}
+
+	// Nothing is logged once the writers are closed.
+	ctx, cancel = context.WithCancel(context.Background())
+	stdout, stderr = logger.CmdWriters(ctx)
+	stdout.Close()
+	stderr.Close()
+	cancel()
+	select {
+	case <-canceled:
+		t.Error("got a cancellation warning after the writers were closed")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestCancellationLoggingDisabled(t *testing.T) {
+	logger, buf := newBufferLogger()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handler := Middleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, `/`, nil).WithContext(ctx))
+	if e := decodeLine(t, buf); e[`message`] != requestMsg {
+		t.Errorf("got %v, want only the request entry", e)
+	}
+}
+
+func TestCancellationLoggingMiddlewarePanic(t *testing.T) {
+	hook, canceled := canceledHook()
+	logger, _ := newBufferLogger(WithCancellationLogging(), hook)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handler := Middleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	func() {
+		defer func() { recover() }()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, `/`, nil).WithContext(ctx))
+	}()
+
+	cancel()
+	select {
+	case <-canceled:
+		t.Error("got a cancellation warning after the handler panicked")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
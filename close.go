@@ -0,0 +1,44 @@
+package log
+
+import (
+	"io"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+// Close flushes the logger and releases what its options hold: it stops the
+// flushing of WithBufferedWriter after a last flush, stops the goroutine of
+// WithErrorWebhook, dropping the entries still queued, and closes the files
+// and connections opened by WithRotatingFile and WithSyslog. Writers passed
+// to WithWriter aren't closed. Close it once, when the program is done
+// logging; loggers derived from l share these resources, and calls after the
+// first return the first result.
+func (l *Logger) Close() error {
+	o := l.opts
+	o.closeOnce.Do(func() {
+		err := l.base.Sync()
+		for _, sink := range []zapcore.WriteSyncer{o.sink, o.errSink} {
+			err = multierr.Append(err, closeSink(sink))
+		}
+		if c, ok := o.webhookCore.(*webhookCore); ok {
+			c.stop()
+		}
+		o.closeErr = err
+	})
+	return o.closeErr
+}
+
+// closeSink stops a buffer around the sink and closes the sink if it's owned
+// by the logger.
+func closeSink(ws zapcore.WriteSyncer) error {
+	var err error
+	if b, ok := ws.(*zapcore.BufferedWriteSyncer); ok {
+		err = b.Stop()
+	}
+	switch s := unwrapSink(ws).(type) {
+	case *rotatingFile, *syslogWriter:
+		err = multierr.Append(err, s.(io.Closer).Close())
+	}
+	return err
+}
@@ -0,0 +1,41 @@
+package log
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClose(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewLogger(WithWriter(buf), WithBufferedWriter(time.Hour, 0))
+	logger.Infow(`buffered`)
+	if err := logger.With(String(`k`, `v`)).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if e := decodeLine(t, buf); e[`message`] != `buffered` {
+		t.Errorf("got %v, want the buffered entry flushed", e)
+	}
+	if err := logger.Close(); err != nil {
+		t.Errorf("got %v from the second Close, want nil", err)
+	}
+}
+
+func TestCloseFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `app.log`)
+	logger := NewLogger(WithRotatingFile(path, 0, 0, 0))
+	logger.Infow(`written`)
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if f := logger.opts.writer.(*rotatingFile); f.file != nil {
+		t.Error("got the log file still open after Close")
+	}
+	if entries := readLog(t, path); len(entries) != 1 {
+		t.Errorf("got %d entries in the file, want 1", len(entries))
+	}
+	if err := logger.Close(); err != nil {
+		t.Errorf("got %v from the second Close, want nil", err)
+	}
+}
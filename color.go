@@ -0,0 +1,41 @@
+package log
+
+import (
+	"io"
+	"os"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// WithColor turns ANSI colors for the level of development loggers on or off.
+// By default levels are colored only when the logger writes to a terminal, so
+// output piped to a file or collected by CI stays free of escape codes.
+func WithColor(enabled bool) Option {
+	return func(o *options) {
+		o.color = &enabled
+	}
+}
+
+// colorLevels reports whether development output should be colored.
+func (o *options) colorLevels() bool {
+	if o.color != nil {
+		return *o.color
+	}
+	return o.terminal
+}
+
+// isTerminal reports whether w is a character device such as a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// colorEncoderConfig returns a copy of cfg encoding levels with colors.
+func colorEncoderConfig(cfg zapcore.EncoderConfig) zapcore.EncoderConfig {
+	cfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	return cfg
+}
@@ -0,0 +1,46 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWithColor(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		development bool
+		opts        []Option
+		colored     bool
+	}{
+		{`development default`, true, nil, false},
+		{`development enabled`, true, []Option{WithColor(true)}, true},
+		{`development disabled`, true, []Option{WithColor(false)}, false},
+		{`production enabled`, false, []Option{WithColor(true)}, false},
+	} {
+		buf := &bytes.Buffer{}
+		opts := append([]Option{WithWriter(buf)}, tt.opts...)
+		logger := NewLogger(opts...)
+		if tt.development {
+			logger = NewDevelopmentLogger(opts...)
+		}
+		logger.Error(context.Background(), `colored`)
+		if got := strings.Contains(buf.String(), "\x1b["); got != tt.colored {
+			t.Errorf("%s: got ANSI codes %v in %q, want %v", tt.name, got, buf, tt.colored)
+		}
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), `app.log`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if isTerminal(f) || isTerminal(&bytes.Buffer{}) {
+		t.Error("got a terminal for a file or a buffer")
+	}
+}
@@ -36,3 +36,45 @@ var (
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
 )
+
+// encoderConfig returns a copy of cfg that also knows the names of levels
+// registered with RegisterLevel.
+func encoderConfig(cfg zapcore.EncoderConfig) zapcore.EncoderConfig {
+	cfg.EncodeLevel = levelEncoder(cfg.EncodeLevel)
+	return cfg
+}
+
+// EncoderKeys renames the keys of the entry metadata. Empty keys keep their
+// default names, which differ between NewLogger and NewDevelopmentLogger.
+type EncoderKeys struct {
+	Time       string `json:"time,omitempty" yaml:"time,omitempty"`
+	Level      string `json:"level,omitempty" yaml:"level,omitempty"`
+	Name       string `json:"name,omitempty" yaml:"name,omitempty"`
+	Caller     string `json:"caller,omitempty" yaml:"caller,omitempty"`
+	Message    string `json:"message,omitempty" yaml:"message,omitempty"`
+	Stacktrace string `json:"stacktrace,omitempty" yaml:"stacktrace,omitempty"`
+}
+
+// WithEncoderKeys renames the keys of the entry metadata, e.g. "msg" instead
+// of "message" to match what a log pipeline expects. It applies to the json,
+// console and logfmt formats; the keys of gelf are fixed by GELF.
+func WithEncoderKeys(keys EncoderKeys) Option {
+	return func(o *options) {
+		o.keys = &keys
+		for _, k := range []struct {
+			key *string
+			to  string
+		}{
+			{&o.encoderConfig.TimeKey, keys.Time},
+			{&o.encoderConfig.LevelKey, keys.Level},
+			{&o.encoderConfig.NameKey, keys.Name},
+			{&o.encoderConfig.CallerKey, keys.Caller},
+			{&o.encoderConfig.MessageKey, keys.Message},
+			{&o.encoderConfig.StacktraceKey, keys.Stacktrace},
+		} {
+			if k.to != `` {
+				*k.key = k.to
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package log
+
+import (
+	"context"
+)
+
+type loggerKey struct{}
+
+type fieldsKey struct{}
+
+// WithContext returns a copy of ctx carrying l, so a request-scoped logger
+// with pre-attached fields can be retrieved down the call chain with
+// FromContext.
+func WithContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx by WithContext, or the default
+// logger L if there is none.
+func FromContext(ctx context.Context) *Logger {
+	if ctx == nil {
+		return L()
+	}
+	if l, ok := ctx.Value(loggerKey{}).(*Logger); ok && l != nil {
+		return l
+	}
+	return L()
+}
+
+// nonNil returns ctx, or context.Background if a caller passed nil, so the
+// context-aware methods log the unknown trace ID instead of panicking.
+func nonNil(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
+
+// WithFields returns a copy of ctx carrying fields, such as the tenant or the
+// region of a request, which the context-aware methods add to every entry.
+// Fields added to a context that already carries some are appended to them.
+// When a logging call passes a field with the same key, the call's field wins
+// and the context's one is dropped.
+func WithFields(ctx context.Context, fields ...Field) context.Context {
+	ctx = nonNil(ctx)
+	prev := contextFields(ctx)
+	return context.WithValue(ctx, fieldsKey{}, append(prev[:len(prev):len(prev)], fields...))
+}
+
+func contextFields(ctx context.Context) []Field {
+	fields, _ := ctx.Value(fieldsKey{}).([]Field)
+	return fields
+}
+
+// appendContextFields appends the fields carried by ctx to fields, except
+// the ones whose key is already used by fields.
+func appendContextFields(ctx context.Context, fields []Field) []Field {
+	n := len(fields)
+outer:
+	for _, cf := range contextFields(ctx) {
+		for _, f := range fields[:n] {
+			if f.Key == cf.Key {
+				continue outer
+			}
+		}
+		fields = append(fields, cf)
+	}
+	return fields
+}
@@ -0,0 +1,57 @@
+package log
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestWithContext(t *testing.T) {
+	logger := NewNopLogger().With(RequestID(`req`))
+	ctx := WithContext(context.Background(), logger)
+	if got := FromContext(ctx); got != logger {
+		t.Errorf("got %p from the context, want the stored logger %p", got, logger)
+	}
+	if got := FromContext(context.Background()); got != L() {
+		t.Errorf("got %p without a stored logger, want L()", got)
+	}
+	// FromContext accepts a nil context.
+	if got := FromContext(nil); got != L() {
+		t.Errorf("got %p for a nil context, want L()", got)
+	}
+}
+
+func TestWithFields(t *testing.T) {
+	ctx := WithFields(context.Background(), String(`tenant`, `acme`), String(`region`, `eu`))
+	ctx = WithFields(ctx, String(`shard`, `7`))
+	a, b := WithFields(ctx, String(`a`, `1`)), WithFields(ctx, String(`b`, `2`))
+	if fa, fb := contextFields(a), contextFields(b); len(fa) != 4 || fa[3].Key != `a` || len(fb) != 4 || fb[3].Key != `b` {
+		t.Errorf("got %v and %v, want siblings not to share their fields", fa, fb)
+	}
+	// A nil context is accepted, like in the logging methods.
+	if fields := contextFields(WithFields(nil, String(`k`, `v`))); len(fields) != 1 {
+		t.Errorf("got %v for a nil context, want the field", fields)
+	}
+
+	logger, buf := newBufferLogger()
+	logger.Info(ctx, `merged`, `user`, `alice`)
+	logger.Info(ctx, `overridden`, `region`, `us`)
+	logger.Infow(`no context`, `k`, `v`)
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	if e := entries[0]; e[`tenant`] != `acme` || e[`region`] != `eu` || e[`shard`] != `7` || e[`user`] != `alice` {
+		t.Errorf("got %v, want the context fields merged with the call's", e)
+	}
+	if n := strings.Count(buf.String(), `"region"`); n != 2 {
+		t.Errorf("got region %d times, want once per context-aware entry", n)
+	}
+	if e := entries[1]; e[`region`] != `us` || e[`tenant`] != `acme` {
+		t.Errorf("got %v, want the call's region to win", e)
+	}
+	if e := entries[2]; e[`tenant`] != nil {
+		t.Errorf("got %v, want no context fields without a context", e)
+	}
+}
@@ -0,0 +1,39 @@
+package log
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"go.uber.org/zap/zapcore"
+)
+
+type correlationIDKey struct{}
+
+// ContextWithCorrelationID returns a context carrying a correlation ID along
+// with the ID. If ctx already carries one it's kept, otherwise a random ID is
+// generated. The context-aware logging methods add the ID as the
+// correlation_id field, which correlates entries even without tracing.
+func ContextWithCorrelationID(ctx context.Context) (context.Context, string) {
+	ctx = nonNil(ctx)
+	if id, ok := correlationIDFromContext(ctx); ok {
+		return ctx, id
+	}
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	id := hex.EncodeToString(b[:])
+	return context.WithValue(ctx, correlationIDKey{}, id), id
+}
+
+// CorrelationID constructs a field that carries a correlation ID.
+func CorrelationID(value string) Field {
+	return Field{Key: `correlation_id`, Type: zapcore.StringType, String: value}
+}
+
+func correlationIDFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return ``, false
+	}
+	id, ok := ctx.Value(correlationIDKey{}).(string)
+	return id, ok
+}
@@ -0,0 +1,37 @@
+package log
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContextWithCorrelationID(t *testing.T) {
+	ctx, id := ContextWithCorrelationID(context.Background())
+	if len(id) != 32 {
+		t.Errorf("got correlation ID %q, want 32 hex digits", id)
+	}
+	if again, same := ContextWithCorrelationID(ctx); same != id || again != ctx {
+		t.Errorf("got ID %q for a context carrying %q, want it kept", same, id)
+	}
+	if _, other := ContextWithCorrelationID(context.Background()); other == id {
+		t.Errorf("got the same ID %q for another context", id)
+	}
+	// A nil context is accepted, like in the logging methods.
+	if ctx, id := ContextWithCorrelationID(nil); ctx == nil || id == `` {
+		t.Errorf("got %v, %q for a nil context, want a context with an ID", ctx, id)
+	}
+
+	// The ID is logged even below the trace fields level.
+	logger, buf := newBufferLogger(WithTraceFieldsMinLevel(ErrorLevel))
+	logger.Info(ctx, `first`)
+	logger.With(String(`k`, `v`)).Warn(ctx, `second`)
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	for _, e := range entries {
+		if e[`correlation_id`] != id {
+			t.Errorf("got correlation_id %v in %v, want %s", e[`correlation_id`], e[`message`], id)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package log
+
+import (
+	"time"
+)
+
+// cpuTime isn't supported on this platform, so CPU usage is reported as zero.
+func cpuTime() time.Duration {
+	return 0
+}
@@ -1,3 +1,4 @@
+//go:build development
 // +build development
 
 package log
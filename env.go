@@ -0,0 +1,47 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"go.uber.org/multierr"
+)
+
+// Environment variables read by NewLoggerFromEnv.
+const (
+	// EnvLevel holds the level name, parsed with ParseLevel.
+	EnvLevel = `LOG_LEVEL`
+	// EnvFormat holds the output format, "json", "console", "logfmt" or "gelf".
+	EnvFormat = `LOG_FORMAT`
+)
+
+// NewLoggerFromEnv builds a production logger configured by the LOG_LEVEL and
+// LOG_FORMAT environment variables, defaulting to info level and JSON output
+// when they're unset or empty. If a variable holds a value that can't be
+// parsed, the default is used for it and the returned error describes the
+// value; the returned logger is usable either way.
+func NewLoggerFromEnv(opts ...Option) (*Logger, error) {
+	var err error
+	level := InfoLevel
+	if s := os.Getenv(EnvLevel); s != `` {
+		lvl, perr := ParseLevel(s)
+		if perr != nil {
+			err = multierr.Append(err, fmt.Errorf(`%s: %w`, EnvLevel, perr))
+		} else {
+			level = lvl
+		}
+	}
+	format := formatJSON
+	if s := strings.ToLower(strings.TrimSpace(os.Getenv(EnvFormat))); s != `` {
+		if validFormat(s) {
+			format = s
+		} else {
+			err = multierr.Append(err, fmt.Errorf(`log: invalid %s %q, want json, console, logfmt or gelf`, EnvFormat, s))
+		}
+	}
+
+	logger := NewLogger(append([]Option{WithFormat(format)}, opts...)...)
+	logger.SetLevel(level)
+	return logger, err
+}
@@ -0,0 +1,36 @@
+package log
+
+import "testing"
+
+func TestNewLoggerFromEnv(t *testing.T) {
+	for _, tt := range []struct {
+		name, level, format string
+		wantLevel           Level
+		wantFormat          string
+		wantErr             bool
+	}{
+		{`unset`, ``, ``, InfoLevel, formatJSON, false},
+		{`level only`, `debug`, ``, DebugLevel, formatJSON, false},
+		{`format only`, ``, `console`, InfoLevel, formatConsole, false},
+		{`both`, `WARNING`, `Logfmt`, WarnLevel, formatLogfmt, false},
+		{`gelf`, `error`, `gelf`, ErrorLevel, formatGELF, false},
+		{`bad level`, `loud`, `console`, InfoLevel, formatConsole, true},
+		{`bad format`, `debug`, `xml`, DebugLevel, formatJSON, true},
+		{`both bad`, `loud`, `xml`, InfoLevel, formatJSON, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvLevel, tt.level)
+			t.Setenv(EnvFormat, tt.format)
+			logger, err := NewLoggerFromEnv()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want an error: %t", err, tt.wantErr)
+			}
+			if logger == nil {
+				t.Fatal("got a nil logger")
+			}
+			if cfg := logger.Config(); cfg.Level != tt.wantLevel || cfg.Format != tt.wantFormat {
+				t.Errorf("got level %v and format %s, want %v and %s", cfg.Level, cfg.Format, tt.wantLevel, tt.wantFormat)
+			}
+		})
+	}
+}
@@ -2,7 +2,16 @@ package log
 
 import (
 	"context"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
 
+	"go.opentelemetry.io/otel/baggage"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -36,10 +45,58 @@ type (
 // Since byte/uint8 and rune/int32 are aliases, Any can't differentiate between
 // them. To minimize surprises, []byte values are treated as binary blobs, byte
 // values are treated as uint8, and runes are always treated as integers.
+//
+// Keys registered with RegisterFieldEncoder are rendered by their encoder
+// instead.
 func Any(key string, value interface{}) Field {
+	if enc, ok := fieldEncoders.load()[key]; ok {
+		return enc(value)
+	}
 	return zap.Any(key, value)
 }
 
+// fieldEncoders holds the registered encoders in a map that is replaced, never
+// modified, so Any reads it without locking. mu serializes the registrations.
+var fieldEncoders encoderRegistry
+
+type encoderRegistry struct {
+	mu sync.Mutex
+	m  atomic.Value // map[string]func(value interface{}) Field
+}
+
+func (r *encoderRegistry) load() map[string]func(value interface{}) Field {
+	m, _ := r.m.Load().(map[string]func(value interface{}) Field)
+	return m
+}
+
+// RegisterFieldEncoder makes Any, and therefore the key-value pairs passed to
+// the logging methods, render every value logged under key with enc. This
+// keeps a key like "price" formatted the same way at every call site.
+// Registering a nil encoder removes the key's encoder.
+func RegisterFieldEncoder(key string, enc func(value interface{}) Field) {
+	fieldEncoders.mu.Lock()
+	defer fieldEncoders.mu.Unlock()
+	old := fieldEncoders.load()
+	m := make(map[string]func(value interface{}) Field, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+	if enc == nil {
+		delete(m, key)
+	} else {
+		m[key] = enc
+	}
+	fieldEncoders.m.Store(m)
+}
+
+// Reflect constructs a field that always encodes value with reflection,
+// which the JSON encoder does with encoding/json. Unlike Any, which picks a
+// specialized encoding for known types and only falls back to reflection, it
+// makes the cost explicit at the call site. Use it for complex structs.
+func Reflect(key string, value interface{}) Field {
+	return zap.Reflect(key, value)
+}
+
 // Object constructs a field with the given key and ObjectMarshaler. It
 // provides a flexible, but still type-safe and efficient, way to add map- or
 // struct-like user-defined types to the logging context. The struct's
@@ -48,6 +105,31 @@ func Object(key string, val ObjectMarshaler) Field {
 	return Field{Key: key, Type: zapcore.ObjectMarshalerType, Interface: val}
 }
 
+// Objects constructs a field with the given key, holding a list of the
+// provided ObjectMarshalers. Each element's MarshalLogObject method is called
+// lazily, so logging a batch of items avoids the reflection path of Any.
+func Objects[T ObjectMarshaler](key string, items []T) Field {
+	return zap.Objects(key, items)
+}
+
+// Strings constructs a field that carries a slice of strings, in order. A nil
+// slice is encoded as an empty array.
+func Strings(key string, values []string) Field {
+	return zap.Strings(key, values)
+}
+
+// Ints constructs a field that carries a slice of ints, in order. A nil slice
+// is encoded as an empty array.
+func Ints(key string, values []int) Field {
+	return zap.Ints(key, values)
+}
+
+// Float64s constructs a field that carries a slice of float64s, in order. A
+// nil slice is encoded as an empty array.
+func Float64s(key string, values []float64) Field {
+	return zap.Float64s(key, values)
+}
+
 // Binary constructs a field that carries an opaque binary blob.
 //
 // Binary data is serialized in an encoding-appropriate format. For example,
@@ -73,6 +155,104 @@ func ByteString(key string, val []byte) Field {
 	return Field{Key: key, Type: zapcore.ByteStringType, Interface: val}
 }
 
+// String constructs a field with the given key and value.
+func String(key string, value string) Field {
+	return Field{Key: key, Type: zapcore.StringType, String: value}
+}
+
+// Stringp constructs a field that carries a *string. The returned Field will
+// safely and explicitly represent `nil` when appropriate.
+func Stringp(key string, value *string) Field {
+	if value == nil {
+		return nilField(key)
+	}
+	return String(key, *value)
+}
+
+// Int constructs a field with the given key and value.
+func Int(key string, value int) Field {
+	return Int64(key, int64(value))
+}
+
+// Intp constructs a field that carries a *int. The returned Field will safely
+// and explicitly represent `nil` when appropriate.
+func Intp(key string, value *int) Field {
+	if value == nil {
+		return nilField(key)
+	}
+	return Int(key, *value)
+}
+
+// Int64 constructs a field with the given key and value.
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Type: zapcore.Int64Type, Integer: value}
+}
+
+// Int64p constructs a field that carries a *int64. The returned Field will
+// safely and explicitly represent `nil` when appropriate.
+func Int64p(key string, value *int64) Field {
+	if value == nil {
+		return nilField(key)
+	}
+	return Int64(key, *value)
+}
+
+// Uint64 constructs a field with the given key and value.
+func Uint64(key string, value uint64) Field {
+	return Field{Key: key, Type: zapcore.Uint64Type, Integer: int64(value)}
+}
+
+// Float64 constructs a field that carries a float64. The way the
+// floating-point value is represented is encoder-dependent, so marshaling is
+// necessarily lazy.
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Type: zapcore.Float64Type, Integer: int64(math.Float64bits(value))}
+}
+
+// Float64p constructs a field that carries a *float64. The returned Field will
+// safely and explicitly represent `nil` when appropriate.
+func Float64p(key string, value *float64) Field {
+	if value == nil {
+		return nilField(key)
+	}
+	return Float64(key, *value)
+}
+
+// Duration constructs a field with the given key and value. The encoder
+// controls how the duration is serialized: as seconds in production and as a
+// human-readable string in development.
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Type: zapcore.DurationType, Integer: int64(value)}
+}
+
+// Durationp constructs a field that carries a *time.Duration. The returned
+// Field will safely and explicitly represent `nil` when appropriate.
+func Durationp(key string, value *time.Duration) Field {
+	if value == nil {
+		return nilField(key)
+	}
+	return Duration(key, *value)
+}
+
+// Skip constructs a no-op field, which is often useful when handling invalid
+// inputs in other Field constructors.
+func Skip() Field {
+	return Field{Type: zapcore.SkipType}
+}
+
+// Cond returns f if pred is true and a no-op field otherwise, so a field can
+// be added conditionally without branching around the logging call:
+//
+//	logger.Info(ctx, "done", log.Cond(verbose, log.Any("payload", p)))
+//
+// f is built either way, so keep it cheap to construct.
+func Cond(pred bool, f Field) Field {
+	if pred {
+		return f
+	}
+	return Skip()
+}
+
 // Namespace creates a named, isolated scope within the logger's context. All
 // subsequent fields will be added to the new namespace.
 //
@@ -82,6 +262,13 @@ func Namespace(key string) Field {
 	return Field{Key: key, Type: zapcore.NamespaceType}
 }
 
+// Under constructs a field that nests the given fields in an object under the
+// namespace key. Unlike Namespace, it only affects the provided fields, not
+// every field added after it.
+func Under(namespace string, fields ...Field) Field {
+	return Object(namespace, fieldList(fields))
+}
+
 func Method(value string) Field {
 	return Field{Key: `method`, Type: zapcore.StringType, String: value}
 }
@@ -105,12 +292,249 @@ func Offset(value int64) Field {
 	return Field{Key: `offset`, Type: zapcore.Int64Type, Integer: value}
 }
 
+// Status constructs a field that carries an HTTP status code along with its
+// class ("2xx", "4xx", ...) under status_class, so dashboards can group by
+// class. Codes outside 100-599 get the class "unknown".
+func Status(code int) Field {
+	return Field{Type: zapcore.InlineMarshalerType, Interface: httpStatus(code)}
+}
+
+type httpStatus int
+
+func (s httpStatus) MarshalLogObject(enc ObjectEncoder) error {
+	enc.AddInt64(`status`, int64(s))
+	if s < 100 || s > 599 {
+		enc.AddString(`status_class`, `unknown`)
+	} else {
+		enc.AddString(`status_class`, strconv.Itoa(int(s)/100)+`xx`)
+	}
+	return nil
+}
+
+// StatusCode constructs a field that carries a bare HTTP or gRPC status code
+// under status. Use Status to add the class of an HTTP status code as well.
+func StatusCode(code int) Field {
+	return Field{Key: `status`, Type: zapcore.Int64Type, Integer: int64(code)}
+}
+
+// Latency constructs a field that carries d in milliseconds under latency_ms,
+// independently of the duration encoder. Fractions of a millisecond are kept.
+func Latency(d time.Duration) Field {
+	return Float64(`latency_ms`, float64(d)/float64(time.Millisecond))
+}
+
+// httpHeaders are the request headers logged by HTTPRequest. Headers that may
+// carry credentials, such as Authorization and Cookie, are left out.
+var httpHeaders = []string{
+	`Accept`,
+	`Content-Length`,
+	`Content-Type`,
+	`Referer`,
+	`User-Agent`,
+	`X-Forwarded-For`,
+	`X-Request-Id`,
+}
+
+// HTTPRequest constructs a field that carries the method, URL path and remote
+// address of r under request, along with the values of a fixed list of
+// headers that can't hold credentials. Authorization, Cookie and any other
+// header are never logged.
+func HTTPRequest(r *http.Request) Field {
+	if r == nil {
+		return nilField(`request`)
+	}
+	return Object(`request`, httpRequest{r})
+}
+
+type httpRequest struct {
+	r *http.Request
+}
+
+func (r httpRequest) MarshalLogObject(enc ObjectEncoder) error {
+	enc.AddString(`method`, r.r.Method)
+	if r.r.URL != nil {
+		enc.AddString(`path`, r.r.URL.Path)
+	}
+	enc.AddString(`remote_addr`, r.r.RemoteAddr)
+	return enc.AddObject(`headers`, httpHeaderList(r.r.Header))
+}
+
+type httpHeaderList http.Header
+
+func (h httpHeaderList) MarshalLogObject(enc ObjectEncoder) error {
+	for _, key := range httpHeaders {
+		if v := http.Header(h).Get(key); v != `` {
+			enc.AddString(key, v)
+		}
+	}
+	return nil
+}
+
+// HTTPResponse constructs a field that carries the status code and the size
+// in bytes of an HTTP response under response.
+func HTTPResponse(status int, size int64) Field {
+	return Object(`response`, httpResponse{status: status, size: size})
+}
+
+type httpResponse struct {
+	status int
+	size   int64
+}
+
+func (r httpResponse) MarshalLogObject(enc ObjectEncoder) error {
+	enc.AddInt64(`status`, int64(r.status))
+	enc.AddInt64(`size`, r.size)
+	return nil
+}
+
+// Throughput constructs a field that carries the number of bytes processed in
+// d along with the resulting rate in bytes per second. The rate is omitted
+// when d isn't positive.
+func Throughput(bytes int64, d time.Duration) Field {
+	return Object(`throughput`, throughput{bytes: bytes, d: d})
+}
+
+type throughput struct {
+	bytes int64
+	d     time.Duration
+}
+
+func (t throughput) MarshalLogObject(enc ObjectEncoder) error {
+	enc.AddInt64(`bytes`, t.bytes)
+	enc.AddDuration(`duration`, t.d)
+	if t.d > 0 {
+		enc.AddFloat64(`bytes_per_sec`, float64(t.bytes)/t.d.Seconds())
+	}
+	return nil
+}
+
+// GeoPoint constructs a field that carries geographic coordinates as an
+// object with lat and lng. Coordinates outside [-90, 90] and [-180, 180] are
+// logged as given, with an additional invalid flag.
+func GeoPoint(key string, lat, lng float64) Field {
+	return Object(key, geoPoint{lat: lat, lng: lng})
+}
+
+type geoPoint struct {
+	lat, lng float64
+}
+
+func (p geoPoint) MarshalLogObject(enc ObjectEncoder) error {
+	enc.AddFloat64(`lat`, p.lat)
+	enc.AddFloat64(`lng`, p.lng)
+	if !(p.lat >= -90 && p.lat <= 90 && p.lng >= -180 && p.lng <= 180) {
+		enc.AddBool(`invalid`, true)
+	}
+	return nil
+}
+
+// Decimal constructs a field that carries a pre-formatted decimal number, such
+// as "12.30", as a string so that it's logged exactly as given instead of
+// being rounded like a float.
+func Decimal(key string, value string) Field {
+	return String(key, value)
+}
+
+// Amount constructs a field that carries a monetary amount as an object with
+// the amount in integer cents, the minor unit of the currency, and the
+// currency code, e.g. Amount("price", 1299, "EUR"). Amounts are never
+// converted to floats, so they stay exact.
+func Amount(key string, cents int64, currency string) Field {
+	return Object(key, amount{cents: cents, currency: currency})
+}
+
+type amount struct {
+	cents    int64
+	currency string
+}
+
+func (a amount) MarshalLogObject(enc ObjectEncoder) error {
+	enc.AddInt64(`cents`, a.cents)
+	enc.AddString(`currency`, a.currency)
+	return nil
+}
+
 func ProductID(value uint64) Field {
 	return Field{Key: `product_id`, Type: zapcore.Uint64Type, Integer: int64(value)}
 }
 
+// Stack constructs a field that carries the stacktrace of the current
+// goroutine, for entries below the level at which stacktraces are added
+// automatically.
+func Stack(key string) Field {
+	return zap.StackSkip(key, 1)
+}
+
+// StackSkip is like Stack but also skips the given number of frames from the
+// top of the stacktrace, e.g. those of logging helpers.
+func StackSkip(key string, skip int) Field {
+	return zap.StackSkip(key, skip+1)
+}
+
+// UserID constructs a field that carries the ID of the authenticated user.
+func UserID(value uint64) Field {
+	return Field{Key: `user_id`, Type: zapcore.Uint64Type, Integer: int64(value)}
+}
+
+// UserIDString is like UserID for systems with non-numeric user IDs, such as
+// UUIDs.
+func UserIDString(value string) Field {
+	return Field{Key: `user_id`, Type: zapcore.StringType, String: value}
+}
+
+// DBResult constructs a field that carries the outcome of a SQL statement as
+// an object with rows_affected and last_insert_id. Pass -1 as lastInsertID
+// for drivers that don't support it, and it's omitted.
+func DBResult(rowsAffected, lastInsertID int64) Field {
+	return Object(`db_result`, dbResult{rowsAffected: rowsAffected, lastInsertID: lastInsertID})
+}
+
+type dbResult struct {
+	rowsAffected, lastInsertID int64
+}
+
+func (r dbResult) MarshalLogObject(enc ObjectEncoder) error {
+	enc.AddInt64(`rows_affected`, r.rowsAffected)
+	if r.lastInsertID != -1 {
+		enc.AddInt64(`last_insert_id`, r.lastInsertID)
+	}
+	return nil
+}
+
+// Error constructs a field that carries an error under the key "error". The
+// error keeps its type, so the encoder also renders the verbose form of
+// errors that provide one and the causes of combined errors. A nil error
+// adds nothing.
 func Error(err error) Field {
-	return Field{Key: `error`, Type: zapcore.StringType, String: err.Error()}
+	return NamedError(`error`, err)
+}
+
+// NamedError constructs a field that carries an error like Error, but under
+// the given key, so several errors can be logged in one entry. A nil error
+// adds nothing.
+func NamedError(key string, err error) Field {
+	if err == nil {
+		return Skip()
+	}
+	return Field{Key: key, Type: zapcore.ErrorType, Interface: err}
+}
+
+// Errors constructs a field that carries the messages of errs as an array,
+// in order. Nil errors are skipped, which makes it handy for collecting
+// validation failures.
+func Errors(key string, errs []error) Field {
+	return Field{Key: key, Type: zapcore.ArrayMarshalerType, Interface: errArray(errs)}
+}
+
+type errArray []error
+
+func (errs errArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for i := range errs {
+		if errs[i] != nil {
+			enc.AppendString(errs[i].Error())
+		}
+	}
+	return nil
 }
 
 func Count(count int) Field {
@@ -121,6 +545,33 @@ func Query(query string) Field {
 	return Field{Key: `query`, Type: zapcore.StringType, String: query}
 }
 
+// QueryTruncated constructs a query field like Query, but cuts queries longer
+// than maxLen bytes so that they fit in maxLen bytes with an ellipsis at the
+// end, so huge statements and the values inlined in their tail stay out of
+// the logs. The query is never cut in the middle of a UTF-8 sequence, and the
+// ellipsis is left out if maxLen is too short for it. A maxLen that isn't
+// positive leaves the query whole.
+func QueryTruncated(query string, maxLen int) Field {
+	if maxLen > 0 && len(query) > maxLen {
+		marker := `…`
+		if maxLen < len(marker) {
+			marker = ``
+		}
+		cut := maxLen - len(marker)
+		for cut > 0 && !utf8.RuneStart(query[cut]) {
+			cut--
+		}
+		query = query[:cut] + marker
+	}
+	return Query(query)
+}
+
+// Args constructs a field that carries the number of parameters bound to a
+// query under args, without their values.
+func Args(n int) Field {
+	return Field{Key: `args`, Type: zapcore.Int64Type, Integer: int64(n)}
+}
+
 func File(fileName string) Field {
 	return Field{Key: `file`, Type: zapcore.StringType, String: fileName}
 }
@@ -129,6 +580,7 @@ const NoTraceId = `unknown`
 
 // TraceId - extract trace ID from span
 func TraceId(ctx context.Context) Field {
+	ctx = nonNil(ctx)
 	span := trace.SpanFromContext(ctx)
 
 	if span.SpanContext().TraceID().IsValid() {
@@ -145,3 +597,69 @@ func TraceId(ctx context.Context) Field {
 		}
 	}
 }
+
+// nilField constructs a field that represents an explicit nil.
+func nilField(key string) Field {
+	return Field{Key: key, Type: zapcore.ReflectType}
+}
+
+type fieldList []Field
+
+func (fs fieldList) MarshalLogObject(enc ObjectEncoder) error {
+	for i := range fs {
+		fs[i].AddTo(enc)
+	}
+	return nil
+}
+
+// SpanId - extract span ID from span
+func SpanId(ctx context.Context) Field {
+	ctx = nonNil(ctx)
+	span := trace.SpanFromContext(ctx)
+
+	if span.SpanContext().SpanID().IsValid() {
+		return Field{
+			Key:    `spanId`,
+			Type:   zapcore.StringType,
+			String: span.SpanContext().SpanID().String(),
+		}
+	}
+	return Field{
+		Key:    `spanId`,
+		Type:   zapcore.StringType,
+		String: NoTraceId,
+	}
+}
+
+// sampled reports whether ctx carries a sampled span.
+func sampled(ctx context.Context) bool {
+	return trace.SpanContextFromContext(nonNil(ctx)).IsSampled()
+}
+
+// TraceContext returns both the TraceId and SpanId fields of ctx.
+func TraceContext(ctx context.Context) []Field {
+	return []Field{TraceId(ctx), SpanId(ctx)}
+}
+
+// Baggage returns the members of the OpenTelemetry baggage of ctx with the
+// given keys as string fields, skipping the missing ones. With no keys, it
+// returns every member, with the key prefixed by "baggage.", sorted by key.
+func Baggage(ctx context.Context, keys ...string) []Field {
+	bag := baggage.FromContext(nonNil(ctx))
+	if len(keys) == 0 {
+		members := bag.Members()
+		sort.Slice(members, func(i, j int) bool { return members[i].Key() < members[j].Key() })
+		fields := make([]Field, 0, len(members))
+		for _, m := range members {
+			fields = append(fields, String(`baggage.`+m.Key(), m.Value()))
+		}
+		return fields
+	}
+	fields := make([]Field, 0, len(keys))
+	for _, key := range keys {
+		if m := bag.Member(key); m.Key() != `` {
+			fields = append(fields, String(key, m.Value()))
+		}
+	}
+	return fields
+}
@@ -0,0 +1,578 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+// encodeFields encodes fields with the production encoder and decodes them
+// back from JSON.
+func encodeFields(t *testing.T, fields ...Field) map[string]interface{} {
+	t.Helper()
+	buf, err := zapcore.NewJSONEncoder(productionEncoderConfig).EncodeEntry(zapcore.Entry{}, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer buf.Free()
+	var m map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("%q isn't a JSON object: %v", buf.Bytes(), err)
+	}
+	delete(m, `level`)
+	delete(m, `time`)
+	delete(m, `message`)
+	return m
+}
+
+type item struct {
+	id   int
+	name string
+}
+
+func (i item) MarshalLogObject(enc ObjectEncoder) error {
+	enc.AddInt(`id`, i.id)
+	enc.AddString(`name`, i.name)
+	return nil
+}
+
+func TestObjects(t *testing.T) {
+	got := encodeFields(t, Objects(`items`, []item{{1, `a`}, {2, `b`}}))
+	want := map[string]interface{}{`items`: []interface{}{
+		map[string]interface{}{`id`: 1.0, `name`: `a`},
+		map[string]interface{}{`id`: 2.0, `name`: `b`},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestUnder(t *testing.T) {
+	got := encodeFields(t, Under(`db`, String(`table`, `users`), Int(`rows`, 2)), String(`table`, `orders`))
+	want := map[string]interface{}{
+		`db`:    map[string]interface{}{`table`: `users`, `rows`: 2.0},
+		`table`: `orders`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestRegisterFieldEncoder(t *testing.T) {
+	RegisterFieldEncoder(`price`, func(value interface{}) Field {
+		return Decimal(`price`, fmt.Sprintf(`%.2f`, value))
+	})
+	defer RegisterFieldEncoder(`price`, nil)
+
+	logger, buf := newBufferLogger()
+	logger.Info(context.Background(), `key-value`, `price`, 3.5)
+	logger.Info(context.Background(), `field`, Any(`price`, 3.5))
+	logger.Infow(`sweetened`, `price`, 3.5)
+	entries := decodeLines(t, buf)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	for _, entry := range entries {
+		if entry[`price`] != `3.50` {
+			t.Errorf("got price %v in %v, want 3.50", entry[`price`], entry[`message`])
+		}
+	}
+
+	RegisterFieldEncoder(`price`, nil)
+	if got := encodeFields(t, Any(`price`, 3.5))[`price`]; got != 3.5 {
+		t.Errorf("got price %v after removing the encoder, want 3.5", got)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	for _, tt := range []struct {
+		code  int
+		class string
+	}{
+		{100, `1xx`},
+		{200, `2xx`},
+		{204, `2xx`},
+		{301, `3xx`},
+		{404, `4xx`},
+		{503, `5xx`},
+		{599, `5xx`},
+		{0, `unknown`},
+		{99, `unknown`},
+		{600, `unknown`},
+		{-1, `unknown`},
+	} {
+		got := encodeFields(t, Status(tt.code))
+		want := map[string]interface{}{`status`: float64(tt.code), `status_class`: tt.class}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Status(%d): got %v, want %v", tt.code, got, want)
+		}
+	}
+}
+
+func TestThroughput(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		d    time.Duration
+		want map[string]interface{}
+	}{
+		{`rate`, 2 * time.Second, map[string]interface{}{`bytes`: 1000.0, `duration`: 2.0, `bytes_per_sec`: 500.0}},
+		{`zero duration`, 0, map[string]interface{}{`bytes`: 1000.0, `duration`: 0.0}},
+	} {
+		got := encodeFields(t, Throughput(1000, tt.d))[`throughput`]
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGeoPoint(t *testing.T) {
+	for _, tt := range []struct {
+		lat, lng float64
+		invalid  bool
+	}{
+		{52.52, 13.405, false},
+		{-90, 180, false},
+		{90.5, 0, true},
+		{0, -180.1, true},
+	} {
+		want := map[string]interface{}{`lat`: tt.lat, `lng`: tt.lng}
+		if tt.invalid {
+			want[`invalid`] = true
+		}
+		if got := encodeFields(t, GeoPoint(`at`, tt.lat, tt.lng))[`at`]; !reflect.DeepEqual(got, want) {
+			t.Errorf("GeoPoint(%v, %v): got %v, want %v", tt.lat, tt.lng, got, want)
+		}
+	}
+}
+
+func TestDBResult(t *testing.T) {
+	got := encodeFields(t, DBResult(3, 17))[`db_result`]
+	want := map[string]interface{}{`rows_affected`: 3.0, `last_insert_id`: 17.0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	got = encodeFields(t, DBResult(3, -1))[`db_result`]
+	want = map[string]interface{}{`rows_affected`: 3.0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v without a last insert ID, want %v", got, want)
+	}
+}
+
+func TestString(t *testing.T) {
+	s := `value`
+	for _, tt := range []struct {
+		field Field
+		want  interface{}
+	}{
+		{String(`k`, `value`), `value`},
+		{String(`k`, ``), ``},
+		{Stringp(`k`, &s), `value`},
+		{Stringp(`k`, nil), nil},
+	} {
+		got, ok := encodeFields(t, tt.field)[`k`]
+		if !ok || got != tt.want {
+			t.Errorf("got %v, %v for %+v, want %v", got, ok, tt.field, tt.want)
+		}
+	}
+	if f := String(`k`, `v`); f.Type != zapcore.StringType {
+		t.Errorf("got type %v, want StringType", f.Type)
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	i, i64, f64 := -7, int64(math.MinInt64), 2.5
+	for _, tt := range []struct {
+		name    string
+		field   Field
+		typ     zapcore.FieldType
+		integer int64
+	}{
+		{`Int`, Int(`k`, -7), zapcore.Int64Type, -7},
+		{`Int64`, Int64(`k`, math.MaxInt64), zapcore.Int64Type, math.MaxInt64},
+		{`Uint64`, Uint64(`k`, math.MaxUint64), zapcore.Uint64Type, -1},
+		{`Float64`, Float64(`k`, 2.5), zapcore.Float64Type, int64(math.Float64bits(2.5))},
+		{`Intp`, Intp(`k`, &i), zapcore.Int64Type, -7},
+		{`Int64p`, Int64p(`k`, &i64), zapcore.Int64Type, math.MinInt64},
+		{`Float64p`, Float64p(`k`, &f64), zapcore.Float64Type, int64(math.Float64bits(2.5))},
+	} {
+		if tt.field.Key != `k` || tt.field.Type != tt.typ || tt.field.Integer != tt.integer {
+			t.Errorf("%s: got %+v, want type %v and integer %d", tt.name, tt.field, tt.typ, tt.integer)
+		}
+	}
+
+	for name, f := range map[string]Field{`Intp`: Intp(`k`, nil), `Int64p`: Int64p(`k`, nil), `Float64p`: Float64p(`k`, nil)} {
+		if got, ok := encodeFields(t, f)[`k`]; !ok || got != nil {
+			t.Errorf("%s(nil): got %v, %v, want null", name, got, ok)
+		}
+	}
+}
+
+func TestDuration(t *testing.T) {
+	d := 1500 * time.Millisecond
+	prod, prodBuf := newBufferLogger()
+	prod.Infow(`elapsed`, Duration(`d`, d), Durationp(`p`, &d), Durationp(`nil`, nil))
+	dev, devBuf := newDevelopmentBufferLogger()
+	dev.Infow(`elapsed`, Duration(`d`, d))
+
+	if e := decodeLine(t, prodBuf); e[`d`] != 1.5 || e[`p`] != 1.5 || e[`nil`] != nil {
+		t.Errorf("got %v in production, want 1.5 seconds and null", e)
+	}
+	if e := decodeLine(t, devBuf); e[`d`] != `1.5s` {
+		t.Errorf("got %v in development, want 1.5s", e[`d`])
+	}
+}
+
+func TestError(t *testing.T) {
+	base := errors.New(`connection refused`)
+	err := fmt.Errorf(`query users: %w`, base)
+	f := Error(err)
+	if f.Type != zapcore.ErrorType || !errors.Is(f.Interface.(error), base) {
+		t.Errorf("got %+v, want an error field keeping the chain", f)
+	}
+	if got := encodeFields(t, f)[`error`]; got != `query users: connection refused` {
+		t.Errorf("got %v, want the wrapped message", got)
+	}
+
+	combined := encodeFields(t, Error(multierr.Combine(err, errors.New(`timeout`))))
+	causes, _ := combined[`errorCauses`].([]interface{})
+	if len(causes) != 2 {
+		t.Errorf("got %v, want the causes of the combined error", combined)
+	}
+
+	if got := encodeFields(t, Error(nil)); len(got) != 0 {
+		t.Errorf("got %v for a nil error, want nothing", got)
+	}
+}
+
+func TestNamedError(t *testing.T) {
+	logger, buf := newBufferLogger()
+	logger.Error(context.Background(), `shutdown failed`,
+		NamedError(`db_err`, errors.New(`db closed`)),
+		NamedError(`cache_err`, errors.New(`cache closed`)),
+		NamedError(`nil_err`, nil))
+
+	e := decodeLine(t, buf)
+	if e[`db_err`] != `db closed` || e[`cache_err`] != `cache closed` {
+		t.Errorf("got %v, want both errors under their keys", e)
+	}
+	if v, ok := e[`nil_err`]; ok {
+		t.Errorf("got nil_err=%v, want nothing for a nil error", v)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	errs := []error{nil, errors.New(`name is empty`), nil, errors.New(`age is negative`), nil}
+	got := encodeFields(t, Errors(`validation`, errs))[`validation`]
+	want := []interface{}{`name is empty`, `age is negative`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := encodeFields(t, Errors(`validation`, nil))[`validation`]; !reflect.DeepEqual(got, []interface{}{}) {
+		t.Errorf("got %v for no errors, want an empty array", got)
+	}
+}
+
+func TestSpanId(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{`span`, spanContext(true), testSpanID},
+		{`no span`, context.Background(), NoTraceId},
+		{`nil context`, nil, NoTraceId},
+	} {
+		if f := SpanId(tt.ctx); f.Key != `spanId` || f.String != tt.want {
+			t.Errorf("%s: got %+v, want spanId %s", tt.name, f, tt.want)
+		}
+	}
+}
+
+func TestTraceContext(t *testing.T) {
+	got := encodeFields(t, TraceContext(spanContext(true))...)
+	want := map[string]interface{}{`traceId`: testTraceID, `spanId`: testSpanID}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	logger, buf := newBufferLogger()
+	logger.Info(spanContext(true), `with span`)
+	logger.Info(context.Background(), `without span`)
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if e := entries[0]; e[`traceId`] != testTraceID || e[`spanId`] != testSpanID {
+		t.Errorf("got %v, want both IDs", e)
+	}
+	if e := entries[1]; e[`traceId`] != NoTraceId || e[`spanId`] != nil {
+		t.Errorf("got %v, want an unknown trace ID and no span ID", e)
+	}
+}
+
+func TestUserID(t *testing.T) {
+	if f := UserID(math.MaxUint64); f.Key != `user_id` || f.Type != zapcore.Uint64Type {
+		t.Errorf("got %+v, want a user_id uint64 field", f)
+	}
+	if got := encodeFields(t, UserID(42))[`user_id`]; got != 42.0 {
+		t.Errorf("got %v, want 42", got)
+	}
+	f := UserIDString(`9b2c5f7e-1d1a-4c55-9a51-0b7f7a3e9c11`)
+	if f.Key != `user_id` || f.Type != zapcore.StringType {
+		t.Errorf("got %+v, want a user_id string field", f)
+	}
+	if got := encodeFields(t, f)[`user_id`]; got != f.String {
+		t.Errorf("got %v, want %s", got, f.String)
+	}
+}
+
+// stackFromHelper returns a stack skipping its own frame.
+func stackFromHelper() Field {
+	return StackSkip(`stack`, 1)
+}
+
+func TestStack(t *testing.T) {
+	for name, f := range map[string]Field{`Stack`: Stack(`stack`), `StackSkip`: stackFromHelper()} {
+		stack, _ := encodeFields(t, f)[`stack`].(string)
+		if !strings.HasPrefix(stack, `github.com/vsjadeja/log.TestStack`) {
+			t.Errorf("%s: got %q, want a stack starting at the test", name, stack)
+		}
+	}
+}
+
+func TestReflect(t *testing.T) {
+	type address struct {
+		City string `json:"city"`
+		Zip  string `json:"zip"`
+	}
+	type user struct {
+		Name    string   `json:"name"`
+		Address address  `json:"address"`
+		Tags    []string `json:"tags"`
+	}
+	f := Reflect(`user`, user{Name: `alice`, Address: address{City: `Berlin`, Zip: `10115`}, Tags: []string{`admin`}})
+	if f.Type != zapcore.ReflectType {
+		t.Errorf("got type %v, want ReflectType", f.Type)
+	}
+	got := encodeFields(t, f)[`user`]
+	want := map[string]interface{}{
+		`name`:    `alice`,
+		`address`: map[string]interface{}{`city`: `Berlin`, `zip`: `10115`},
+		`tags`:    []interface{}{`admin`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSlices(t *testing.T) {
+	got := encodeFields(t,
+		Strings(`strings`, []string{`b`, `a`, `c`}),
+		Ints(`ints`, []int{3, -1, 2}),
+		Float64s(`floats`, []float64{2.5, 0, -1.5}),
+	)
+	want := map[string]interface{}{
+		`strings`: []interface{}{`b`, `a`, `c`},
+		`ints`:    []interface{}{3.0, -1.0, 2.0},
+		`floats`:  []interface{}{2.5, 0.0, -1.5},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	got = encodeFields(t, Strings(`strings`, nil), Ints(`ints`, nil), Float64s(`floats`, nil))
+	empty := []interface{}{}
+	want = map[string]interface{}{`strings`: empty, `ints`: empty, `floats`: empty}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v for nil slices, want empty arrays", got)
+	}
+}
+
+func TestCond(t *testing.T) {
+	if f := Skip(); f.Type != zapcore.SkipType {
+		t.Errorf("got %+v, want a skipped field", f)
+	}
+	logger, buf := newBufferLogger()
+	for _, verbose := range []bool{true, false} {
+		buf.Reset()
+		logger.Infow(`done`, Cond(verbose, String(`payload`, `p`)), `k`, `v`)
+		e := decodeLine(t, buf)
+		if _, ok := e[`payload`]; ok != verbose || e[`k`] != `v` {
+			t.Errorf("got %v with verbose %v, want the payload only if verbose", e, verbose)
+		}
+	}
+}
+
+func TestBaggage(t *testing.T) {
+	session, err := baggage.NewMember(`session.id`, `s1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := baggage.NewMember(`user`, `alice`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bag, err := baggage.New(user, session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+
+	for _, tt := range []struct {
+		name string
+		ctx  context.Context
+		keys []string
+		want []Field
+	}{
+		{`keys`, ctx, []string{`session.id`, `missing`, `user`}, []Field{String(`session.id`, `s1`), String(`user`, `alice`)}},
+		{`all`, ctx, nil, []Field{String(`baggage.session.id`, `s1`), String(`baggage.user`, `alice`)}},
+		{`no baggage`, context.Background(), []string{`user`}, []Field{}},
+		{`nil context`, nil, nil, []Field{}},
+	} {
+		if got := Baggage(tt.ctx, tt.keys...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	logger, buf := newBufferLogger()
+	logger.Info(ctx, `with baggage`, Baggage(ctx, `session.id`)[0])
+	if e := decodeLine(t, buf); e[`session.id`] != `s1` {
+		t.Errorf("got %v, want the baggage member", e)
+	}
+}
+
+func TestLatency(t *testing.T) {
+	for _, tt := range []struct {
+		d    time.Duration
+		want float64
+	}{
+		{0, 0},
+		{1500 * time.Microsecond, 1.5},
+		{2 * time.Second, 2000},
+		{250 * time.Nanosecond, 0.00025},
+	} {
+		got := encodeFields(t, Latency(tt.d))
+		if want := map[string]interface{}{`latency_ms`: tt.want}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Latency(%v): got %v, want %v", tt.d, got, want)
+		}
+	}
+
+	// The duration encoder doesn't apply.
+	logger, buf := newDevelopmentBufferLogger()
+	logger.Infow(`served`, Latency(time.Second))
+	if e := decodeLine(t, buf); e[`latency_ms`] != 1000.0 {
+		t.Errorf("got %v in development, want 1000 milliseconds", e)
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	f := StatusCode(404)
+	if f.Key != `status` || f.Type != zapcore.Int64Type {
+		t.Errorf("got %+v, want a status integer field", f)
+	}
+	if got := encodeFields(t, f); !reflect.DeepEqual(got, map[string]interface{}{`status`: 404.0}) {
+		t.Errorf("got %v, want only status=404", got)
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	for _, s := range []string{`12.30`, `0.1`, `1234567890123456789.99`} {
+		if got := encodeFields(t, Decimal(`price`, s))[`price`]; got != s {
+			t.Errorf("got %v, want %s exactly", got, s)
+		}
+	}
+}
+
+func TestAmount(t *testing.T) {
+	got := encodeFields(t, Amount(`price`, 1299, `EUR`))[`price`]
+	if want := map[string]interface{}{`cents`: 1299.0, `currency`: `EUR`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// The cents are encoded as an integer, without going through a float.
+	buf, err := zapcore.NewJSONEncoder(productionEncoderConfig).EncodeEntry(zapcore.Entry{}, []Field{Amount(`total`, math.MaxInt64, `USD`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer buf.Free()
+	if want := `"total":{"cents":9223372036854775807,"currency":"USD"}`; !strings.Contains(buf.String(), want) {
+		t.Errorf("got %s, want %s", buf, want)
+	}
+}
+
+func TestHTTPRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, `/login?next=/home`, nil)
+	r.RemoteAddr = `10.0.0.1:5555`
+	r.Header.Set(`User-Agent`, `curl/8.0`)
+	r.Header.Set(`X-Request-Id`, `req-1`)
+	r.Header.Set(`Authorization`, `Bearer secret`)
+	r.Header.Set(`Cookie`, `session=secret`)
+	r.Header.Set(`X-Api-Key`, `secret`)
+
+	got := encodeFields(t, HTTPRequest(r), HTTPResponse(http.StatusCreated, 42))
+	want := map[string]interface{}{
+		`request`: map[string]interface{}{
+			`method`:      http.MethodPost,
+			`path`:        `/login`,
+			`remote_addr`: `10.0.0.1:5555`,
+			`headers`:     map[string]interface{}{`User-Agent`: `curl/8.0`, `X-Request-Id`: `req-1`},
+		},
+		`response`: map[string]interface{}{`status`: 201.0, `size`: 42.0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if got, ok := encodeFields(t, HTTPRequest(nil))[`request`]; !ok || got != nil {
+		t.Errorf("got %v, %v for a nil request, want null", got, ok)
+	}
+}
+
+func TestQueryTruncated(t *testing.T) {
+	const query = `SELECT * FROM users WHERE email = 'alice@example.com'`
+	for _, tt := range []struct {
+		name   string
+		query  string
+		maxLen int
+		want   string
+	}{
+		{`shorter`, query, len(query) + 1, query},
+		{`boundary`, query, len(query), query},
+		{`longer`, query, len(query) - 1, query[:len(query)-4] + `…`},
+		{`cut`, query, 22, `SELECT * FROM users…`},
+		{`no limit`, query, 0, query},
+		{`negative`, query, -1, query},
+		{`utf-8`, `SELECT 'äöü'`, 12, `SELECT '…`},
+		{`utf-8 boundary`, `SELECT 'äöü'`, 13, `SELECT 'ä…`},
+		{`no room for the ellipsis`, query, 2, `SE`},
+		{`only the ellipsis`, query, 3, `…`},
+	} {
+		f := QueryTruncated(tt.query, tt.maxLen)
+		if f.Key != `query` || f.String != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, f.String, tt.want)
+		}
+		if tt.maxLen > 0 && len(f.String) > tt.maxLen {
+			t.Errorf("%s: got %d bytes, want at most %d", tt.name, len(f.String), tt.maxLen)
+		}
+	}
+}
+
+func TestArgs(t *testing.T) {
+	got := encodeFields(t, QueryTruncated(`SELECT * FROM users WHERE email = $1`, 0), Args(1))
+	want := map[string]interface{}{`query`: `SELECT * FROM users WHERE email = $1`, `args`: 1.0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want only the query and the number of arguments", got)
+	}
+}
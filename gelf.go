@@ -0,0 +1,186 @@
+package log
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+const formatGELF = `gelf`
+
+// Syslog severities, as used by GELF and syslog.
+const (
+	severityEmergency = 0
+	severityAlert     = 1
+	severityCritical  = 2
+	severityError     = 3
+	severityWarning   = 4
+	severityInfo      = 6
+	severityDebug     = 7
+)
+
+// syslogSeverity maps a level to a syslog severity. Custom levels map like
+// the nearest built-in level below them.
+func syslogSeverity(level Level) int {
+	switch {
+	case level >= zapcore.FatalLevel:
+		return severityEmergency
+	case level >= zapcore.PanicLevel:
+		return severityAlert
+	case level >= zapcore.DPanicLevel:
+		return severityCritical
+	case level >= zapcore.ErrorLevel:
+		return severityError
+	case level >= zapcore.WarnLevel:
+		return severityWarning
+	case level >= zapcore.InfoLevel:
+		return severityInfo
+	default:
+		return severityDebug
+	}
+}
+
+var gelfEncoderConfig = zapcore.EncoderConfig{
+	TimeKey:       `timestamp`,
+	LevelKey:      `level`,
+	NameKey:       `_logger`,
+	CallerKey:     `_caller`,
+	FunctionKey:   zapcore.OmitKey,
+	MessageKey:    `short_message`,
+	StacktraceKey: `full_message`,
+	LineEnding:    zapcore.DefaultLineEnding,
+	EncodeLevel: func(level Level, enc zapcore.PrimitiveArrayEncoder) {
+		enc.AppendInt(syslogSeverity(level))
+	},
+	EncodeTime:     zapcore.EpochTimeEncoder,
+	EncodeDuration: zapcore.SecondsDurationEncoder,
+	EncodeCaller:   zapcore.ShortCallerEncoder,
+}
+
+// gelfEncoder encodes entries as GELF 1.1 documents for Graylog. Fields are
+// sent as additional fields, with their keys prefixed by an underscore, and
+// the stacktrace as the full message. GELF only allows strings and numbers,
+// so the fields of objects and namespaces are flattened, e.g. _db_rows, and
+// arrays and reflected values are sent as their JSON text.
+type gelfEncoder struct {
+	gelfFields
+	json zapcore.Encoder
+}
+
+// gelfFields prefixes the keys of the fields added to an encoder, to make
+// them additional fields. prefix holds the keys of the enclosing objects and
+// namespaces.
+type gelfFields struct {
+	enc    zapcore.ObjectEncoder
+	prefix string
+}
+
+// gelfAdditional adds the fields of an entry as additional fields.
+type gelfAdditional struct {
+	fields []Field
+	prefix string
+}
+
+func (a gelfAdditional) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	e := &gelfFields{enc: enc, prefix: a.prefix}
+	for _, f := range a.fields {
+		f.AddTo(e)
+	}
+	return nil
+}
+
+func newGELFEncoder() zapcore.Encoder {
+	enc := zapcore.NewJSONEncoder(gelfEncoderConfig)
+	host, err := os.Hostname()
+	if err != nil {
+		host = `unknown`
+	}
+	enc.AddString(`version`, `1.1`)
+	enc.AddString(`host`, host)
+	return &gelfEncoder{gelfFields{enc: enc}, enc}
+}
+
+// gelfKey returns the additional field name for key. GELF reserves _id.
+func gelfKey(key string) string {
+	if key == `id` {
+		return `__id`
+	}
+	return `_` + key
+}
+
+func (e *gelfEncoder) Clone() zapcore.Encoder {
+	enc := e.json.Clone()
+	return &gelfEncoder{gelfFields{enc: enc, prefix: e.prefix}, enc}
+}
+
+func (e *gelfEncoder) EncodeEntry(ent zapcore.Entry, fields []Field) (*buffer.Buffer, error) {
+	if len(fields) == 0 {
+		return e.json.EncodeEntry(ent, nil)
+	}
+	return e.json.EncodeEntry(ent, []Field{zap.Inline(gelfAdditional{fields, e.prefix})})
+}
+
+func (e *gelfFields) key(key string) string {
+	return gelfKey(e.prefix + key)
+}
+
+func (e *gelfFields) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	m := zapcore.NewMapObjectEncoder()
+	if err := m.AddArray(key, arr); err != nil {
+		return err
+	}
+	return e.AddReflected(key, m.Fields[key])
+}
+
+func (e *gelfFields) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	return obj.MarshalLogObject(&gelfFields{enc: e.enc, prefix: e.prefix + key + `_`})
+}
+
+// AddReflected adds objects and arrays as their JSON text.
+func (e *gelfFields) AddReflected(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if len(data) > 0 && (data[0] == '{' || data[0] == '[') {
+		e.enc.AddString(e.key(key), string(data))
+		return nil
+	}
+	return e.enc.AddReflected(e.key(key), value)
+}
+
+func (e *gelfFields) OpenNamespace(key string) { e.prefix += key + `_` }
+
+func (e *gelfFields) AddBinary(key string, value []byte) { e.enc.AddBinary(e.key(key), value) }
+func (e *gelfFields) AddByteString(key string, value []byte) {
+	e.enc.AddByteString(e.key(key), value)
+}
+func (e *gelfFields) AddBool(key string, value bool) { e.enc.AddBool(e.key(key), value) }
+func (e *gelfFields) AddComplex128(key string, value complex128) {
+	e.enc.AddComplex128(e.key(key), value)
+}
+func (e *gelfFields) AddComplex64(key string, value complex64) {
+	e.enc.AddComplex64(e.key(key), value)
+}
+func (e *gelfFields) AddDuration(key string, value time.Duration) {
+	e.enc.AddDuration(e.key(key), value)
+}
+func (e *gelfFields) AddFloat64(key string, value float64) { e.enc.AddFloat64(e.key(key), value) }
+func (e *gelfFields) AddFloat32(key string, value float32) { e.enc.AddFloat32(e.key(key), value) }
+func (e *gelfFields) AddInt(key string, value int)         { e.enc.AddInt(e.key(key), value) }
+func (e *gelfFields) AddInt64(key string, value int64)     { e.enc.AddInt64(e.key(key), value) }
+func (e *gelfFields) AddInt32(key string, value int32)     { e.enc.AddInt32(e.key(key), value) }
+func (e *gelfFields) AddInt16(key string, value int16)     { e.enc.AddInt16(e.key(key), value) }
+func (e *gelfFields) AddInt8(key string, value int8)       { e.enc.AddInt8(e.key(key), value) }
+func (e *gelfFields) AddString(key, value string)          { e.enc.AddString(e.key(key), value) }
+func (e *gelfFields) AddTime(key string, value time.Time)  { e.enc.AddTime(e.key(key), value) }
+func (e *gelfFields) AddUint(key string, value uint)       { e.enc.AddUint(e.key(key), value) }
+func (e *gelfFields) AddUint64(key string, value uint64)   { e.enc.AddUint64(e.key(key), value) }
+func (e *gelfFields) AddUint32(key string, value uint32)   { e.enc.AddUint32(e.key(key), value) }
+func (e *gelfFields) AddUint16(key string, value uint16)   { e.enc.AddUint16(e.key(key), value) }
+func (e *gelfFields) AddUint8(key string, value uint8)     { e.enc.AddUint8(e.key(key), value) }
+func (e *gelfFields) AddUintptr(key string, value uintptr) { e.enc.AddUintptr(e.key(key), value) }
@@ -0,0 +1,89 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestGELF(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewLogger(WithWriter(buf), WithFormat(formatGELF), WithClock(newTestClock()), WithCaller(false))
+	logger.Named(`api`).With(String(`service`, `users`)).Warnw(`slow request`,
+		Status(404), zap.Inline(item{7, `x`}), Under(`db`, Int(`rows`, 2)), Any(`tags`, []string{`a`, `b`}),
+		zap.Reflect(`point`, struct{ X int }{1}), zap.Namespace(`ctx`), Int(`id`, 3))
+
+	host, err := os.Hostname()
+	if err != nil {
+		host = `unknown`
+	}
+	got := decodeLine(t, buf)
+	want := map[string]interface{}{
+		`version`:       `1.1`,
+		`host`:          host,
+		`timestamp`:     1577836800.0,
+		`level`:         4.0,
+		`short_message`: `slow request`,
+		`_logger`:       `api`,
+		`_service`:      `users`,
+		`_status`:       404.0,
+		`_status_class`: `4xx`,
+		`__id`:          7.0,
+		`_name`:         `x`,
+		`_db_rows`:      2.0,
+		`_tags`:         `["a","b"]`,
+		`_point`:        `{"X":1}`,
+		`_ctx_id`:       3.0,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	for _, tt := range []struct {
+		level Level
+		want  int
+	}{
+		{DebugLevel - 1, 7},
+		{DebugLevel, 7},
+		{InfoLevel, 6},
+		{WarnLevel, 4},
+		{ErrorLevel, 3},
+		{DPanicLevel, 2},
+		{PanicLevel, 1},
+		{FatalLevel, 0},
+		{FatalLevel + 1, 0},
+	} {
+		if got := syslogSeverity(tt.level); got != tt.want {
+			t.Errorf("syslogSeverity(%v): got %d, want %d", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestGELFStacktrace(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewLogger(WithWriter(buf), WithFormat(formatGELF))
+	logger.Errorw(`failed`)
+	e := decodeLine(t, buf)
+	if e[`level`] != 3.0 || e[`full_message`] == nil || e[`_caller`] == nil {
+		t.Errorf("got %v, want the stacktrace as the full message and the caller", e)
+	}
+	if _, ok := e[`stacktrace`]; ok {
+		t.Errorf("got %v, want no stacktrace key", e)
+	}
+}
+
+func TestGELFNamespaceWith(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewLogger(WithWriter(buf), WithFormat(formatGELF), WithCaller(false))
+	logger.With(zap.Namespace(`req`), String(`path`, `/`)).Infow(`served`, `status`, 200)
+
+	e := decodeLine(t, buf)
+	if e[`_req_path`] != `/` || e[`_req_status`] != 200.0 {
+		t.Errorf("got %v, want the fields flattened under the namespace", e)
+	}
+}
@@ -0,0 +1,40 @@
+// Package grpclog provides gRPC interceptors that log calls through a
+// log.Logger. It's a separate package so the log package doesn't depend on
+// gRPC.
+package grpclog
+
+import (
+	"context"
+	"time"
+
+	"github.com/vsjadeja/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	callMsg       = `grpc call`
+	callFailedMsg = `grpc call failed`
+)
+
+// UnaryServerInterceptor returns an interceptor that logs every unary call
+// once it completes, with the method, the duration and the status code. Calls
+// that return an error are logged at error level with the Error field. The
+// trace ID is taken from the incoming context.
+func UnaryServerInterceptor(l *log.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		fields := []interface{}{
+			log.Method(info.FullMethod),
+			log.Duration(`duration`, time.Since(start)),
+			log.String(`code`, status.Code(err).String()),
+		}
+		if err != nil {
+			l.Error(ctx, callFailedMsg, append(fields, log.Error(err))...)
+		} else {
+			l.Info(ctx, callMsg, fields...)
+		}
+		return resp, err
+	}
+}
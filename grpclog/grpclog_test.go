@@ -0,0 +1,76 @@
+package grpclog
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/vsjadeja/log"
+	"github.com/vsjadeja/log/logtest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	logger, logs := logtest.NewObserver(log.InfoLevel)
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpc.UnaryInterceptor(UnaryServerInterceptor(logger)))
+	hs := health.NewServer()
+	hs.SetServingStatus(`svc`, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, hs)
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient(`passthrough:///bufconn`,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+	ctx := context.Background()
+
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: `svc`}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: `unknown`}); status.Code(err) != codes.NotFound {
+		t.Fatalf("got %v, want NotFound", err)
+	}
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	for i, want := range []struct {
+		level   log.Level
+		message string
+		code    string
+	}{
+		{log.InfoLevel, callMsg, `OK`},
+		{log.ErrorLevel, callFailedMsg, `NotFound`},
+	} {
+		e := entries[i]
+		fields := e.ContextMap()
+		if e.Level != want.level || e.Message != want.message || fields[`code`] != want.code ||
+			fields[`method`] != healthpb.Health_Check_FullMethodName {
+			t.Errorf("got %v %q %v, want %v %q with code %s", e.Level, e.Message, fields, want.level, want.message, want.code)
+		}
+		if _, ok := fields[`duration`]; !ok {
+			t.Errorf("got %v, want the duration", fields)
+		}
+		if _, ok := fields[`traceId`]; !ok {
+			t.Errorf("got %v, want the trace ID", fields)
+		}
+	}
+	if _, ok := entries[1].ContextMap()[`error`]; !ok {
+		t.Errorf("got %v, want the error", entries[1].ContextMap())
+	}
+}
@@ -0,0 +1,80 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const redacted = `[REDACTED]`
+
+// ConfigHandler returns an HTTP handler that serves the logger's current
+// configuration as JSON on GET, for troubleshooting, including the levels
+// of modules and logger names overridden with SetLevelFor and
+// SetLevelForName. Sensitive sink details, such as the error webhook URL, are
+// redacted.
+func (l *Logger) ConfigHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set(`Allow`, http.MethodGet)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		cfg := l.Config()
+		if cfg.ErrorWebhook != nil {
+			cfg.ErrorWebhook.URL = redacted
+		}
+		w.Header().Set(`Content-Type`, `application/json`)
+		_ = json.NewEncoder(w).Encode(cfg)
+	})
+}
+
+// LevelHandler returns an HTTP handler that reports the logger's level as
+// JSON on GET, e.g. {"level":"info"}, and changes it on PUT with a body of the
+// same form, or a form with a level value, like zap's AtomicLevel handler.
+// Level names are parsed with ParseLevel, so "warning" and the levels of
+// RegisterLevel are accepted. It's safe for concurrent use and reflects
+// SetLevel calls made elsewhere, since it works on the same atomic level.
+// Changes made through it are reported to the OnLevelChange callbacks.
+func (l *Logger) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			l.level.ServeHTTP(w, r)
+			return
+		}
+		enc := json.NewEncoder(w)
+		lvl, err := decodeLevel(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = enc.Encode(struct {
+				Error string `json:"error"`
+			}{err.Error()})
+			return
+		}
+		l.modules.setLevel(l.level, lvl)
+		_ = enc.Encode(struct {
+			Level Level `json:"level"`
+		}{l.Level()})
+	})
+}
+
+// decodeLevel parses the level of a PUT request to LevelHandler.
+func decodeLevel(r *http.Request) (Level, error) {
+	var name string
+	if r.Header.Get(`Content-Type`) == `application/x-www-form-urlencoded` {
+		name = r.FormValue(`level`)
+	} else {
+		var body struct {
+			Level *string `json:"level"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			return InfoLevel, fmt.Errorf(`malformed request body: %w`, err)
+		}
+		if body.Level == nil {
+			return InfoLevel, errors.New(`must specify logging level`)
+		}
+		name = *body.Level
+	}
+	return ParseLevel(name)
+}
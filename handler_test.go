@@ -0,0 +1,118 @@
+package log
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigHandler(t *testing.T) {
+	logger := NewLogger(WithWriter(io.Discard), WithMaxFields(16),
+		WithErrorWebhook(`https://hooks.example.com/secret-token`, ErrorLevel, time.Minute))
+	defer logger.Close()
+	logger.SetLevel(WarnLevel)
+	logger.SetLevelFor(`billing`, DebugLevel)
+	logger.SetLevelForName(`api.auth`, ErrorLevel)
+	srv := httptest.NewServer(logger.ConfigHandler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get(`Content-Type`); ct != `application/json` {
+		t.Errorf("got Content-Type %q, want application/json", ct)
+	}
+	var cfg LoggerConfig
+	if err := json.NewDecoder(resp.Body).Decode(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != WarnLevel || cfg.MaxFields != 16 ||
+		cfg.ModuleLevels[`billing`] != DebugLevel || cfg.NameLevels[`api.auth`] != ErrorLevel {
+		t.Errorf("got config %+v", cfg)
+	}
+	if cfg.ErrorWebhook == nil || cfg.ErrorWebhook.URL != redacted || cfg.ErrorWebhook.Throttle != time.Minute {
+		t.Errorf("got webhook %+v, want the URL redacted", cfg.ErrorWebhook)
+	}
+	if url := logger.Config().ErrorWebhook.URL; url == redacted {
+		t.Error("serving the config redacted the logger's own config")
+	}
+
+	resp, err = http.Post(srv.URL, `application/json`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("got status %d for POST, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	logger := NewLogger(WithWriter(io.Discard))
+	var changes []Level
+	logger.OnLevelChange(func(old, new Level) {
+		changes = append(changes, new)
+	})
+	handler := logger.LevelHandler()
+
+	get := func() string {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, `/`, nil))
+		return strings.TrimSpace(rec.Body.String())
+	}
+	if got := get(); got != `{"level":"info"}` {
+		t.Errorf("got %s, want info", got)
+	}
+	logger.SetLevel(WarnLevel)
+	if got := get(); got != `{"level":"warn"}` {
+		t.Errorf("got %s after SetLevel, want warn", got)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, `/`, strings.NewReader(`{"level":"debug"}`)))
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != `{"level":"debug"}` {
+		t.Errorf("got %d %s for PUT, want 200 and debug", rec.Code, rec.Body)
+	}
+	if logger.Level() != DebugLevel {
+		t.Errorf("got level %v after PUT, want debug", logger.Level())
+	}
+	if len(changes) != 2 || changes[1] != DebugLevel {
+		t.Errorf("got level changes %v, want warn and debug", changes)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, `/`, strings.NewReader(`{"level":"loud"}`)))
+	if rec.Code != http.StatusBadRequest || logger.Level() != DebugLevel {
+		t.Errorf("got %d and level %v for an invalid PUT, want 400 and debug", rec.Code, logger.Level())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, `/`, strings.NewReader(`{"level":"warning"}`)))
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != `{"level":"warn"}` {
+		t.Errorf("got %d %s for PUT warning, want 200 and warn", rec.Code, rec.Body)
+	}
+
+	req := httptest.NewRequest(http.MethodPut, `/`, strings.NewReader(`level=error`))
+	req.Header.Set(`Content-Type`, `application/x-www-form-urlencoded`)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || logger.Level() != ErrorLevel {
+		t.Errorf("got %d and level %v for a form PUT, want 200 and error", rec.Code, logger.Level())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, `/`, strings.NewReader(`{}`)))
+	if rec.Code != http.StatusBadRequest || logger.Level() != ErrorLevel {
+		t.Errorf("got %d and level %v for a PUT without a level, want 400 and error", rec.Code, logger.Level())
+	}
+	if want := []Level{WarnLevel, DebugLevel, WarnLevel, ErrorLevel}; !reflect.DeepEqual(changes, want) {
+		t.Errorf("got level changes %v, want %v", changes, want)
+	}
+}
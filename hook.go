@@ -0,0 +1,16 @@
+package log
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// WithHook calls fn for every entry the logger writes, e.g. to count errors
+// for metrics. Entries filtered out by the level or a sampler don't reach fn.
+// Hooks run synchronously on the logging goroutine, so keep them cheap; an
+// error returned by fn is reported to zap's error output. The option can be
+// given more than once.
+func WithHook(fn func(zapcore.Entry) error) Option {
+	return func(o *options) {
+		o.hooks = append(o.hooks, fn)
+	}
+}
@@ -0,0 +1,53 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWithHook(t *testing.T) {
+	var all, errs int
+	logger, buf := newBufferLogger(
+		WithHook(func(zapcore.Entry) error {
+			all++
+			return nil
+		}),
+		WithHook(func(ent zapcore.Entry) error {
+			if ent.Level >= ErrorLevel {
+				errs++
+			}
+			return nil
+		}),
+	)
+	ctx := context.Background()
+	logger.Debug(ctx, `filtered`)
+	logger.Info(ctx, `info`)
+	logger.Warn(ctx, `warn`)
+	logger.Error(ctx, `error`)
+	logger.Errorw(`error`)
+
+	if n := len(decodeLines(t, buf)); all != n || all != 4 || errs != 2 {
+		t.Errorf("got %d and %d hook calls for %d entries, want 4 and 2", all, errs, n)
+	}
+}
+
+func TestWithHookError(t *testing.T) {
+	errOut := &strings.Builder{}
+	logger, buf := newBufferLogger(WithHook(func(zapcore.Entry) error {
+		return errors.New(`hook failed`)
+	}))
+	logger = logger.WithOptions(zap.ErrorOutput(zapcore.AddSync(errOut)))
+	logger.Infow(`written`)
+
+	if e := decodeLine(t, buf); e[`message`] != `written` {
+		t.Errorf("got %v, want the entry written despite the hook error", e)
+	}
+	if !strings.Contains(errOut.String(), `hook failed`) {
+		t.Errorf("got %q on the error output, want the hook error", errOut)
+	}
+}
@@ -1,11 +1,28 @@
 package log
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
 	"go.uber.org/zap/zapcore"
 )
 
 type (
 	// A Level is a logging priority. Higher levels are more important.
+	//
+	// Level is an alias of zapcore.Level, so it implements
+	// encoding.TextMarshaler and encoding.TextUnmarshaler and can be used in
+	// configuration structs decoded from JSON or YAML:
+	//
+	//	type Config struct {
+	//		Level log.Level `json:"level" yaml:"level"`
+	//	}
+	//
+	// with a value such as "debug" or "error". Text unmarshaling only knows
+	// the built-in names; decode a string and use ParseLevel to accept the
+	// "warning" alias and the levels registered with RegisterLevel.
 	Level = zapcore.Level
 )
 
@@ -29,3 +46,88 @@ const (
 	// FatalLevel logs a message, then calls os.Exit(1).
 	FatalLevel = zapcore.FatalLevel
 )
+
+var customLevels = struct {
+	sync.RWMutex
+	names  map[Level]string
+	levels map[string]Level
+}{
+	names:  map[Level]string{},
+	levels: map[string]Level{},
+}
+
+// RegisterLevel teaches ParseLevel and the level encoders the name of a custom
+// level, e.g. a Trace level below DebugLevel. Built-in levels and their names
+// can't be redefined, and each level and name can only be registered once.
+func RegisterLevel(level Level, name string) error {
+	if name == `` {
+		return errors.New(`log: level name must not be empty`)
+	}
+	if level >= DebugLevel && level <= FatalLevel {
+		return fmt.Errorf(`log: can't rename built-in level %s`, level)
+	}
+	var builtin Level
+	if lower := strings.ToLower(name); lower == `warning` || builtin.UnmarshalText([]byte(lower)) == nil {
+		return fmt.Errorf(`log: level name %q is used by a built-in level`, name)
+	}
+
+	customLevels.Lock()
+	defer customLevels.Unlock()
+	if old, ok := customLevels.names[level]; ok {
+		return fmt.Errorf(`log: level %d is already registered as %q`, level, old)
+	}
+	if old, ok := customLevels.levels[strings.ToLower(name)]; ok {
+		return fmt.Errorf(`log: level name %q is already registered for level %d`, name, old)
+	}
+	customLevels.names[level] = name
+	customLevels.levels[strings.ToLower(name)] = level
+	return nil
+}
+
+// ParseLevel parses a level name, ignoring case: one of the built-in names
+// debug, info, warn (or warning), error, dpanic, panic and fatal, or a name
+// registered with RegisterLevel.
+func ParseLevel(s string) (Level, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	customLevels.RLock()
+	level, ok := customLevels.levels[name]
+	customLevels.RUnlock()
+	if ok {
+		return level, nil
+	}
+	if name == `warning` {
+		return WarnLevel, nil
+	}
+	if name == `` || level.UnmarshalText([]byte(name)) != nil {
+		return InfoLevel, fmt.Errorf(`log: unknown level %q, want one of debug, info, warn, error, dpanic, panic, fatal`, s)
+	}
+	return level, nil
+}
+
+// LevelString returns the name of level: the name registered with
+// RegisterLevel for custom levels, or the lowercase built-in name, which
+// ParseLevel accepts back.
+func LevelString(level Level) string {
+	customLevels.RLock()
+	name, ok := customLevels.names[level]
+	customLevels.RUnlock()
+	if ok {
+		return name
+	}
+	return level.String()
+}
+
+// levelEncoder renders registered custom levels by name and defers to next
+// for everything else.
+func levelEncoder(next zapcore.LevelEncoder) zapcore.LevelEncoder {
+	return func(level Level, enc zapcore.PrimitiveArrayEncoder) {
+		customLevels.RLock()
+		name, ok := customLevels.names[level]
+		customLevels.RUnlock()
+		if ok {
+			enc.AppendString(name)
+			return
+		}
+		next(level, enc)
+	}
+}
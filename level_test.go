@@ -0,0 +1,135 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// traceLevel is a custom level below DebugLevel, registered once per test
+// binary by registerTraceLevel.
+const traceLevel = DebugLevel - 1
+
+var registerTraceLevelOnce sync.Once
+
+func registerTraceLevel(t *testing.T) {
+	t.Helper()
+	var err error
+	registerTraceLevelOnce.Do(func() {
+		err = RegisterLevel(traceLevel, `trace`)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegisterLevel(t *testing.T) {
+	registerTraceLevel(t)
+
+	level, err := ParseLevel(`TRACE`)
+	if err != nil || level != traceLevel {
+		t.Fatalf("got %v, %v from ParseLevel, want %v", level, err, traceLevel)
+	}
+	if name := LevelString(traceLevel); name != `trace` {
+		t.Errorf("got %q from LevelString, want trace", name)
+	}
+
+	logger, buf := newBufferLogger()
+	if err := logger.SetLevelString(`trace`); err != nil {
+		t.Fatal(err)
+	}
+	logger.Log(context.Background(), traceLevel, `traced`)
+	if got := decodeLine(t, buf)[`level`]; got != `trace` {
+		t.Errorf("got level %v, want trace", got)
+	}
+}
+
+func TestRegisterLevelCollisions(t *testing.T) {
+	registerTraceLevel(t)
+
+	for _, tt := range []struct {
+		level Level
+		name  string
+	}{
+		{InfoLevel, `information`},
+		{traceLevel - 1, `warn`},
+		{traceLevel - 1, `Warning`},
+		{traceLevel - 1, `trace`},
+		{traceLevel, `finest`},
+		{traceLevel - 1, ``},
+	} {
+		if err := RegisterLevel(tt.level, tt.name); err == nil {
+			t.Errorf("RegisterLevel(%d, %q) succeeded, want an error", tt.level, tt.name)
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want Level
+	}{
+		{`debug`, DebugLevel},
+		{`info`, InfoLevel},
+		{`warn`, WarnLevel},
+		{`warning`, WarnLevel},
+		{`error`, ErrorLevel},
+		{`dpanic`, DPanicLevel},
+		{`panic`, PanicLevel},
+		{`fatal`, FatalLevel},
+		{`DEBUG`, DebugLevel},
+		{`Warning`, WarnLevel},
+		{` Error `, ErrorLevel},
+	} {
+		got, err := ParseLevel(tt.s)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseLevel(%q): got %v, %v, want %v", tt.s, got, err, tt.want)
+		}
+	}
+	for _, s := range []string{``, `verbose`, `warnings`} {
+		if _, err := ParseLevel(s); err == nil || !strings.Contains(err.Error(), `unknown level`) {
+			t.Errorf("ParseLevel(%q): got error %v, want an unknown level error", s, err)
+		}
+	}
+}
+
+func TestLevelJSON(t *testing.T) {
+	type config struct {
+		Level Level `json:"level"`
+	}
+	for _, level := range []Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, DPanicLevel, PanicLevel, FatalLevel} {
+		b, err := json.Marshal(config{level})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `{"level":"` + LevelString(level) + `"}`; string(b) != want {
+			t.Errorf("got %s, want %s", b, want)
+		}
+		var got config
+		if err := json.Unmarshal(b, &got); err != nil || got.Level != level {
+			t.Errorf("got %v, %v from %s, want %v", got.Level, err, b, level)
+		}
+		if parsed, err := ParseLevel(LevelString(level)); err != nil || parsed != level {
+			t.Errorf("got %v, %v from ParseLevel(LevelString(%v))", parsed, err, level)
+		}
+	}
+
+	var got config
+	if err := json.Unmarshal([]byte(`{"level":"verbose"}`), &got); err == nil {
+		t.Error("got no error for an unknown level")
+	}
+
+	// A string decoded first goes through ParseLevel, which knows the
+	// warning alias.
+	var raw struct {
+		Level string `json:"level"`
+	}
+	if err := json.Unmarshal([]byte(`{"level":"Warning"}`), &raw); err != nil {
+		t.Fatal(err)
+	}
+	if level, err := ParseLevel(raw.Level); err != nil || level != WarnLevel {
+		t.Errorf("got %v, %v for warning, want warn", level, err)
+	}
+}
@@ -0,0 +1,281 @@
+package log
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+var logfmtPool = buffer.NewPool()
+
+// logfmtEncoder encodes entries as logfmt: space separated key=value pairs
+// on one line. Values containing spaces, quotes, equal signs or control
+// characters are quoted. Objects and arrays are rendered as JSON.
+type logfmtEncoder struct {
+	cfg *zapcore.EncoderConfig
+	// buf holds the fields added with With.
+	buf *buffer.Buffer
+	// namespace prefixes the keys of fields added after OpenNamespace.
+	namespace string
+}
+
+func newLogfmtEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
+	return &logfmtEncoder{cfg: &cfg, buf: logfmtPool.Get()}
+}
+
+func (e *logfmtEncoder) Clone() zapcore.Encoder {
+	buf := logfmtPool.Get()
+	_, _ = buf.Write(e.buf.Bytes())
+	return &logfmtEncoder{cfg: e.cfg, buf: buf, namespace: e.namespace}
+}
+
+func (e *logfmtEncoder) EncodeEntry(ent zapcore.Entry, fields []Field) (*buffer.Buffer, error) {
+	line := &logfmtEncoder{cfg: e.cfg, buf: logfmtPool.Get()}
+	if e.cfg.TimeKey != `` && e.cfg.EncodeTime != nil {
+		line.appendEncoded(e.cfg.TimeKey, func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeTime(ent.Time, enc) })
+	}
+	if e.cfg.LevelKey != `` && e.cfg.EncodeLevel != nil {
+		line.appendEncoded(e.cfg.LevelKey, func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeLevel(ent.Level, enc) })
+	}
+	if ent.LoggerName != `` && e.cfg.NameKey != `` {
+		if e.cfg.EncodeName != nil {
+			line.appendEncoded(e.cfg.NameKey, func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeName(ent.LoggerName, enc) })
+		} else {
+			line.AddString(e.cfg.NameKey, ent.LoggerName)
+		}
+	}
+	if ent.Caller.Defined {
+		if e.cfg.CallerKey != `` && e.cfg.EncodeCaller != nil {
+			line.appendEncoded(e.cfg.CallerKey, func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeCaller(ent.Caller, enc) })
+		}
+		if e.cfg.FunctionKey != `` {
+			line.AddString(e.cfg.FunctionKey, ent.Caller.Function)
+		}
+	}
+	if e.cfg.MessageKey != `` {
+		line.AddString(e.cfg.MessageKey, ent.Message)
+	}
+	if e.buf.Len() > 0 {
+		if line.buf.Len() > 0 {
+			line.buf.AppendByte(' ')
+		}
+		_, _ = line.buf.Write(e.buf.Bytes())
+	}
+	line.namespace = e.namespace
+	for i := range fields {
+		fields[i].AddTo(line)
+	}
+	line.namespace = ``
+	if ent.Stack != `` && e.cfg.StacktraceKey != `` {
+		line.AddString(e.cfg.StacktraceKey, ent.Stack)
+	}
+	if e.cfg.LineEnding != `` {
+		line.buf.AppendString(e.cfg.LineEnding)
+	} else {
+		line.buf.AppendString(zapcore.DefaultLineEnding)
+	}
+	return line.buf, nil
+}
+
+// appendKey starts a new pair.
+func (e *logfmtEncoder) appendKey(key string) {
+	if e.buf.Len() > 0 {
+		e.buf.AppendByte(' ')
+	}
+	if e.namespace != `` {
+		e.buf.AppendString(e.namespace)
+		e.buf.AppendByte('.')
+	}
+	e.buf.AppendString(key)
+	e.buf.AppendByte('=')
+}
+
+// appendValue appends s, quoted if needed.
+func (e *logfmtEncoder) appendValue(s string) {
+	if needsQuotes(s) {
+		e.buf.AppendString(strconv.Quote(s))
+		return
+	}
+	e.buf.AppendString(s)
+}
+
+// appendEncoded appends the values appended by encode, such as a level or
+// time encoder, joined by spaces.
+func (e *logfmtEncoder) appendEncoded(key string, encode func(zapcore.PrimitiveArrayEncoder)) {
+	var values logfmtValues
+	encode(&values)
+	e.appendKey(key)
+	e.appendValue(strings.Join(values, ` `))
+}
+
+// appendJSON appends the JSON encoding of v.
+func (e *logfmtEncoder) appendJSON(key string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	e.appendKey(key)
+	e.appendValue(string(b))
+	return nil
+}
+
+func needsQuotes(s string) bool {
+	if s == `` {
+		return true
+	}
+	for _, r := range s {
+		if r == ' ' || r == '=' || r == '"' || r == utf8.RuneError || unicode.IsControl(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *logfmtEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	m := zapcore.NewMapObjectEncoder()
+	if err := m.AddArray(key, arr); err != nil {
+		return err
+	}
+	return e.appendJSON(key, m.Fields[key])
+}
+
+func (e *logfmtEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	m := zapcore.NewMapObjectEncoder()
+	if err := obj.MarshalLogObject(m); err != nil {
+		return err
+	}
+	return e.appendJSON(key, m.Fields)
+}
+
+func (e *logfmtEncoder) AddReflected(key string, value interface{}) error {
+	return e.appendJSON(key, value)
+}
+
+func (e *logfmtEncoder) OpenNamespace(key string) {
+	if e.namespace != `` {
+		key = e.namespace + `.` + key
+	}
+	e.namespace = key
+}
+
+func (e *logfmtEncoder) AddBinary(key string, value []byte) {
+	e.AddString(key, base64.StdEncoding.EncodeToString(value))
+}
+
+func (e *logfmtEncoder) AddByteString(key string, value []byte) {
+	e.AddString(key, string(value))
+}
+
+func (e *logfmtEncoder) AddBool(key string, value bool) {
+	e.appendKey(key)
+	e.buf.AppendBool(value)
+}
+
+func (e *logfmtEncoder) AddComplex128(key string, value complex128) {
+	e.appendKey(key)
+	e.appendValue(strconv.FormatComplex(value, 'g', -1, 128))
+}
+
+func (e *logfmtEncoder) AddComplex64(key string, value complex64) {
+	e.appendKey(key)
+	e.appendValue(strconv.FormatComplex(complex128(value), 'g', -1, 64))
+}
+
+func (e *logfmtEncoder) AddDuration(key string, value time.Duration) {
+	if e.cfg.EncodeDuration == nil {
+		e.AddInt64(key, int64(value))
+		return
+	}
+	e.appendEncoded(key, func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeDuration(value, enc) })
+}
+
+func (e *logfmtEncoder) AddFloat64(key string, value float64) {
+	e.appendKey(key)
+	e.buf.AppendString(formatFloat(value, 64))
+}
+
+func (e *logfmtEncoder) AddFloat32(key string, value float32) {
+	e.appendKey(key)
+	e.buf.AppendString(formatFloat(float64(value), 32))
+}
+
+func (e *logfmtEncoder) AddInt(key string, value int)     { e.AddInt64(key, int64(value)) }
+func (e *logfmtEncoder) AddInt32(key string, value int32) { e.AddInt64(key, int64(value)) }
+func (e *logfmtEncoder) AddInt16(key string, value int16) { e.AddInt64(key, int64(value)) }
+func (e *logfmtEncoder) AddInt8(key string, value int8)   { e.AddInt64(key, int64(value)) }
+
+func (e *logfmtEncoder) AddInt64(key string, value int64) {
+	e.appendKey(key)
+	e.buf.AppendInt(value)
+}
+
+func (e *logfmtEncoder) AddString(key, value string) {
+	e.appendKey(key)
+	e.appendValue(value)
+}
+
+func (e *logfmtEncoder) AddTime(key string, value time.Time) {
+	if e.cfg.EncodeTime == nil {
+		e.AddInt64(key, value.UnixNano())
+		return
+	}
+	e.appendEncoded(key, func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeTime(value, enc) })
+}
+
+func (e *logfmtEncoder) AddUint(key string, value uint)       { e.AddUint64(key, uint64(value)) }
+func (e *logfmtEncoder) AddUint32(key string, value uint32)   { e.AddUint64(key, uint64(value)) }
+func (e *logfmtEncoder) AddUint16(key string, value uint16)   { e.AddUint64(key, uint64(value)) }
+func (e *logfmtEncoder) AddUint8(key string, value uint8)     { e.AddUint64(key, uint64(value)) }
+func (e *logfmtEncoder) AddUintptr(key string, value uintptr) { e.AddUint64(key, uint64(value)) }
+
+func (e *logfmtEncoder) AddUint64(key string, value uint64) {
+	e.appendKey(key)
+	e.buf.AppendUint(value)
+}
+
+func formatFloat(f float64, bitSize int) string {
+	switch {
+	case math.IsNaN(f):
+		return `NaN`
+	case math.IsInf(f, 1):
+		return `+Inf`
+	case math.IsInf(f, -1):
+		return `-Inf`
+	}
+	return strconv.FormatFloat(f, 'f', -1, bitSize)
+}
+
+// logfmtValues collects the values appended by the level, time, duration,
+// caller and name encoders of an EncoderConfig.
+type logfmtValues []string
+
+func (v *logfmtValues) add(s string) { *v = append(*v, s) }
+
+func (v *logfmtValues) AppendBool(b bool)             { v.add(strconv.FormatBool(b)) }
+func (v *logfmtValues) AppendByteString(b []byte)     { v.add(string(b)) }
+func (v *logfmtValues) AppendComplex128(c complex128) { v.add(strconv.FormatComplex(c, 'g', -1, 128)) }
+func (v *logfmtValues) AppendComplex64(c complex64) {
+	v.add(strconv.FormatComplex(complex128(c), 'g', -1, 64))
+}
+func (v *logfmtValues) AppendFloat64(f float64) { v.add(formatFloat(f, 64)) }
+func (v *logfmtValues) AppendFloat32(f float32) { v.add(formatFloat(float64(f), 32)) }
+func (v *logfmtValues) AppendInt(i int)         { v.add(strconv.FormatInt(int64(i), 10)) }
+func (v *logfmtValues) AppendInt64(i int64)     { v.add(strconv.FormatInt(i, 10)) }
+func (v *logfmtValues) AppendInt32(i int32)     { v.add(strconv.FormatInt(int64(i), 10)) }
+func (v *logfmtValues) AppendInt16(i int16)     { v.add(strconv.FormatInt(int64(i), 10)) }
+func (v *logfmtValues) AppendInt8(i int8)       { v.add(strconv.FormatInt(int64(i), 10)) }
+func (v *logfmtValues) AppendString(s string)   { v.add(s) }
+func (v *logfmtValues) AppendUint(u uint)       { v.add(strconv.FormatUint(uint64(u), 10)) }
+func (v *logfmtValues) AppendUint64(u uint64)   { v.add(strconv.FormatUint(u, 10)) }
+func (v *logfmtValues) AppendUint32(u uint32)   { v.add(strconv.FormatUint(uint64(u), 10)) }
+func (v *logfmtValues) AppendUint16(u uint16)   { v.add(strconv.FormatUint(uint64(u), 10)) }
+func (v *logfmtValues) AppendUint8(u uint8)     { v.add(strconv.FormatUint(uint64(u), 10)) }
+func (v *logfmtValues) AppendUintptr(u uintptr) { v.add(strconv.FormatUint(uint64(u), 10)) }
@@ -0,0 +1,40 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestLogfmt(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewLogger(WithWriter(buf), WithFormat(formatLogfmt), WithClock(newTestClock()), WithCaller(false))
+	logger.Named(`api`).With(String(`service`, `users`)).Infow(`request served`,
+		`path`, `/users`,
+		`status`, 200,
+		`latency`, 1500*time.Millisecond,
+		`ok`, true,
+		`query`, `name="a b"`,
+		`empty`, ``,
+		Under(`db`, Int(`rows`, 2)),
+		Strings(`tags`, []string{`a`, `b`}),
+	)
+
+	want := `time=2020-01-01T00:00:00.000Z level=info logger=api message="request served" service=users ` +
+		`path=/users status=200 latency=1.5 ok=true query="name=\"a b\"" empty="" db="{\"rows\":2}" tags="[\"a\",\"b\"]"` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestLogfmtKeys(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewLogger(WithWriter(buf), WithFormat(formatLogfmt), WithClock(newTestClock()), WithCaller(false),
+		WithEncoderKeys(EncoderKeys{Time: `ts`, Level: `lvl`, Message: `msg`}))
+	logger.Warnw(`disk`, Namespace(`usage`), Float64(`percent`, 91.5))
+
+	want := `ts=2020-01-01T00:00:00.000Z lvl=warn msg=disk usage.percent=91.5` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
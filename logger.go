@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sync"
 	"sync/atomic"
 	"unsafe"
 
@@ -26,50 +27,146 @@ func L() *Logger {
 }
 
 type Logger struct {
-	base  *zap.Logger
-	level zap.AtomicLevel
-}
-
-func NewLogger() *Logger {
-	logger := &Logger{level: zap.NewAtomicLevelAt(zapcore.InfoLevel)}
-	logger.base = zap.New(
-		zapcore.NewCore(
-			zapcore.NewJSONEncoder(productionEncoderConfig),
-			nopCloserSink{os.Stderr},
-			logger.level,
-		),
-		zap.AddCaller(),
-		zap.AddCallerSkip(2),
-		zap.AddStacktrace(zapcore.ErrorLevel),
-	)
-	return logger
+	base     *zap.Logger
+	level    zap.AtomicLevel
+	opts     *options
+	progress *progressThrottle
+	modules  *moduleLevels
+	// name and with record what Named and With added to base, so the core
+	// can be rebuilt around another level.
+	name string
+	with []Field
+	// also holds the cores added with AlsoTo.
+	also []alsoCore
+	// truncated counts the fields dropped by With because of WithMaxFields.
+	truncated int
+	// zapOpts holds the options added with WithOptions.
+	zapOpts []zap.Option
+	// safe downgrades panic and fatal entries to errors, see Safe.
+	safe bool
+}
+
+func NewLogger(opts ...Option) *Logger {
+	return newLogger(zapcore.InfoLevel, newOptions(false, opts))
+}
+
+func NewDevelopmentLogger(opts ...Option) *Logger {
+	return newLogger(zapcore.DebugLevel, newOptions(true, opts))
+}
+
+// NewNopLogger returns a logger that writes nothing. Its level still works
+// like the one of other loggers: it starts at InfoLevel and SetLevel, Level
+// and Enabled behave the same.
+func NewNopLogger() *Logger {
+	o := newOptions(false, nil)
+	o.sink = nil
+	return newLogger(zapcore.InfoLevel, o)
 }
 
-func NewDevelopmentLogger() *Logger {
-	logger := &Logger{level: zap.NewAtomicLevelAt(zapcore.DebugLevel)}
-	logger.base = zap.New(
-		zapcore.NewCore(
-			zapcore.NewConsoleEncoder(developmentEncoderConfig),
-			nopCloserSink{os.Stderr},
-			logger.level,
-		),
-		zap.AddCaller(),
-		zap.AddCallerSkip(2),
-		zap.AddStacktrace(zapcore.WarnLevel),
-		zap.Development(),
-	)
+func newLogger(level Level, o *options) *Logger {
+	logger := &Logger{
+		level:    zap.NewAtomicLevelAt(level),
+		opts:     o,
+		progress: newProgressThrottle(),
+		modules:  newModuleLevels(),
+	}
+	logger.base = zap.New(o.newCore(logger.level), o.zapOptions()...)
 	return logger
 }
 
-func NewNopLogger() *Logger {
-	return &Logger{zap.NewNop(), zap.NewAtomicLevel()}
+// rebuild returns a copy of l that writes through a new core gated by level.
+// The name, the fields attached with With and the AlsoTo cores are carried
+// over.
+func (l *Logger) rebuild(level zap.AtomicLevel) *Logger {
+	c := *l
+	c.level = level
+	c.base = zap.New(l.opts.newCore(level), l.opts.zapOptions()...).Named(l.name)
+	// The AlsoTo cores receive the fields attached after AlsoTo was called,
+	// so they're teed in between the With calls, as they were originally.
+	attached := 0
+	for _, a := range l.also {
+		other := a.core
+		c.base = c.base.With(l.with[attached:a.with]...).WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(core, other)
+		}))
+		attached = a.with
+	}
+	c.base = c.base.With(l.with[attached:]...)
+	if len(l.zapOpts) > 0 {
+		c.base = c.base.WithOptions(l.zapOpts...)
+	}
+	return &c
 }
 
 // With creates a child logger and adds structured context to it. Fields added
 // to the child don't affect the parent, and vice versa.
+//
+// Every call clones the encoder and copies the attached fields, so pass all
+// fields to a single With call instead of calling With once per field in a
+// loop:
+//
+//	logger.With(fields...)
 func (l *Logger) With(fields ...Field) *Logger {
-	l.base = l.base.With(fields...)
-	return l
+	fields, dropped := l.opts.limit(l.opts.transform(fields), len(l.with))
+	c := *l
+	c.truncated += dropped
+	c.base = l.base.With(fields...)
+	c.with = append(l.with[:len(l.with):len(l.with)], fields...)
+	return &c
+}
+
+// AlsoTo creates a child logger whose entries are also written to other's
+// core, e.g. to copy a subsystem's entries to a central audit logger. Each
+// core applies its own level, so an entry reaches other only if other's level
+// allows it.
+func (l *Logger) AlsoTo(other *Logger) *Logger {
+	if other == nil {
+		return l
+	}
+	c := *l
+	c.base = l.base.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, other.base.Core())
+	}))
+	c.also = append(l.also[:len(l.also):len(l.also)], alsoCore{core: other.base.Core(), with: len(l.with)})
+	return &c
+}
+
+// alsoCore is a core added with AlsoTo, along with the number of fields that
+// were attached with With before, which the core doesn't get.
+type alsoCore struct {
+	core zapcore.Core
+	with int
+}
+
+// Zap returns the underlying zap logger, for libraries that accept a
+// *zap.Logger. It writes through the same core, so it has the same output,
+// fields and level, and SetLevel on l applies to it. Loggers derived from it
+// with zap's own methods don't reflect back on l, and the features of this
+// package that live outside the core, such as trace fields, key-value
+// checks, redaction and field limits, don't apply to them.
+func (l *Logger) Zap() *zap.Logger {
+	// base skips the frames of this package's logging methods and the
+	// helpers of WithCallerSkip, neither of which zap's callers go through.
+	return l.base.WithOptions(zap.AddCallerSkip(-l.opts.callerSkip))
+}
+
+// WithOptions creates a child logger with zap options applied, e.g. to add
+// hooks, adjust the caller skip or change when stacktraces are captured.
+func (l *Logger) WithOptions(opts ...zap.Option) *Logger {
+	c := *l
+	c.base = l.base.WithOptions(opts...)
+	c.zapOpts = append(l.zapOpts[:len(l.zapOpts):len(l.zapOpts)], opts...)
+	return &c
+}
+
+// Safe creates a child logger that never panics or exits: DPanic, Panic and
+// Fatal entries (and their f/w variants) are logged at ErrorLevel instead.
+// This changes the semantics of the fatal methods, so only use it for code
+// that must not take the process down, such as untrusted plugins.
+func (l *Logger) Safe() *Logger {
+	c := *l
+	c.safe = true
+	return &c
 }
 
 // Sync flushes any buffered log entries.
@@ -82,97 +179,206 @@ func (l *Logger) Level() Level {
 	return l.level.Level()
 }
 
-// SetLevel alters the logging level.
+// Enabled reports whether an entry at the given level would be logged. Use it
+// to skip building expensive fields for disabled levels.
+func (l *Logger) Enabled(level Level) bool {
+	return l.base.Core().Enabled(level)
+}
+
+// SetLevel alters the logging level. The callbacks registered with
+// OnLevelChange are called if the level changes.
 func (l *Logger) SetLevel(level Level) {
-	l.level.SetLevel(level)
+	l.modules.setLevel(l.level, level)
+}
+
+// levelListeners holds the callbacks registered with OnLevelChange for one
+// level.
+type levelListeners struct {
+	mu  sync.Mutex
+	fns []func(old, new Level)
+}
+
+// OnLevelChange registers fn to be called with the old and the new level
+// whenever the level of l changes, e.g. to export the level as a metric. The
+// callbacks belong to the level rather than to l, so they see the changes
+// made through SetLevel, SetLevelString and LevelHandler on every logger
+// sharing the level, such as the loggers derived from l with With or the
+// loggers of the same Module, and the ones made by SetLevelFor and
+// SetLevelForName. Several callbacks can be registered; they're called in
+// order, synchronously, by the goroutine that changed the level, so they must
+// not block.
+func (l *Logger) OnLevelChange(fn func(old, new Level)) {
+	ls := l.modules.listeners(l.level)
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.fns = append(ls.fns[:len(ls.fns):len(ls.fns)], fn)
+}
+
+// listeners returns the callbacks of level.
+func (m *moduleLevels) listeners(level zap.AtomicLevel) *levelListeners {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ls, ok := m.levelListeners[level]
+	if !ok {
+		ls = &levelListeners{}
+		m.levelListeners[level] = ls
+	}
+	return ls
+}
+
+// setLevel sets level to lvl and calls its callbacks if it changed. Only
+// OnLevelChange adds levels to levelListeners, so the levels without
+// callbacks don't pile up there.
+func (m *moduleLevels) setLevel(level zap.AtomicLevel, lvl Level) {
+	m.mu.Lock()
+	ls, ok := m.levelListeners[level]
+	m.mu.Unlock()
+	if !ok {
+		level.SetLevel(lvl)
+		return
+	}
+	ls.mu.Lock()
+	old := level.Level()
+	level.SetLevel(lvl)
+	fns := ls.fns
+	ls.mu.Unlock()
+	if old != lvl {
+		for _, fn := range fns {
+			fn(old, lvl)
+		}
+	}
+}
+
+// SetLevelString parses the level name with ParseLevel and alters the
+// logging level.
+func (l *Logger) SetLevelString(level string) error {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.SetLevel(lvl)
+	return nil
+}
+
+// Clone creates an independent copy of the logger. Unlike the loggers created
+// by With, which share the level of their parent, the clone has its own
+// level, starting at the current level of l, so SetLevel on one doesn't
+// affect the other.
+func (l *Logger) Clone() *Logger {
+	return l.rebuild(zap.NewAtomicLevelAt(l.Level()))
 }
 
 // Named adds a new path segment to the Logger's name and return the new Logger.
-// Segments are joined by periods. By default, Logger are unnamed.
+// Segments are joined by periods. By default, Logger are unnamed. The new
+// Logger has its own level, so SetLevel on it doesn't affect l and vice
+// versa. The level starts at the current level of l, or at the level set for
+// the new name with SetLevelForName. An empty name adds no segment and
+// returns l itself, which keeps sharing its level with the caller.
 func (l *Logger) Named(name string) *Logger {
 	if name == `` {
 		return l
 	}
+	c := l.named(name)
+	level, ok := l.modules.name(c.name)
+	if !ok {
+		level = zap.NewAtomicLevelAt(l.Level())
+	}
+	return c.rebuild(level)
+}
+
+// named returns a copy of l with name appended, sharing the level of l.
+func (l *Logger) named(name string) *Logger {
 	c := *l
 	c.base = l.base.Named(name)
+	if l.name == `` {
+		c.name = name
+	} else {
+		c.name = l.name + `.` + name
+	}
 	return &c
 }
 
+// Log logs a message at the given level, which may be a custom level
+// registered with RegisterLevel.
+func (l *Logger) Log(ctx context.Context, level Level, msg string, kv ...interface{}) {
+	l.logw(nonNil(ctx), level, msg, kv)
+}
+
 // Debug uses fmt.Sprint to construct and log a message.
 func (l *Logger) Debug(ctx context.Context, msg string, kv ...interface{}) {
-	kv = append(kv, TraceId(ctx))
-	l.logw(zapcore.DebugLevel, msg, kv)
+	l.logw(nonNil(ctx), zapcore.DebugLevel, msg, kv)
 }
 
 // Info uses fmt.Sprint to construct and log a message.
 func (l *Logger) Info(ctx context.Context, msg string, kv ...interface{}) {
-	kv = append(kv, TraceId(ctx))
-	l.logw(zapcore.InfoLevel, msg, kv)
+	l.logw(nonNil(ctx), zapcore.InfoLevel, msg, kv)
 }
 
 // Warn uses fmt.Sprint to construct and log a message.
 func (l *Logger) Warn(ctx context.Context, msg string, kv ...interface{}) {
-	kv = append(kv, TraceId(ctx))
-	l.logw(zapcore.WarnLevel, msg, kv)
+	l.logw(nonNil(ctx), zapcore.WarnLevel, msg, kv)
 }
 
 // Error uses fmt.Sprint to construct and log a message.
 func (l *Logger) Error(ctx context.Context, msg string, kv ...interface{}) {
-	kv = append(kv, TraceId(ctx))
-	l.logw(zapcore.ErrorLevel, msg, kv)
+	l.logw(nonNil(ctx), zapcore.ErrorLevel, msg, kv)
 }
 
 // DPanic uses fmt.Sprint to construct and log a message. In development, the
 // logger then panics. (See zapcore.DPanicLevel for details.)
 func (l *Logger) DPanic(ctx context.Context, msg string, kv ...interface{}) {
-	kv = append(kv, TraceId(ctx))
-	l.logw(zapcore.DPanicLevel, msg, kv)
+	l.logw(nonNil(ctx), zapcore.DPanicLevel, msg, kv)
 }
 
 // Panic uses fmt.Sprint to construct and log a message, then panics.
 func (l *Logger) Panic(ctx context.Context, msg string, kv ...interface{}) {
-	kv = append(kv, TraceId(ctx))
-	l.logw(zapcore.PanicLevel, msg, kv)
+	l.logw(nonNil(ctx), zapcore.PanicLevel, msg, kv)
 }
 
 // Fatal uses fmt.Sprint to construct and log a message, then calls os.Exit.
 func (l *Logger) Fatal(ctx context.Context, msg string, kv ...interface{}) {
-	kv = append(kv, TraceId(ctx))
-	l.logw(zapcore.FatalLevel, msg, kv)
+	l.logw(nonNil(ctx), zapcore.FatalLevel, msg, kv)
 }
 
-//Deprecated: Debugf uses fmt.Sprintf to log a templated message.
+// Deprecated: Debugf uses fmt.Sprintf to log a templated message.
+// Use Sugared().Debugf instead.
 func (l *Logger) Debugf(format string, args ...interface{}) {
 	l.logf(zapcore.DebugLevel, format, args)
 }
 
-//Deprecated: Infof uses fmt.Sprintf to log a templated message.
+// Deprecated: Infof uses fmt.Sprintf to log a templated message.
+// Use Sugared().Infof instead.
 func (l *Logger) Infof(format string, args ...interface{}) {
 	l.logf(zapcore.InfoLevel, format, args)
 }
 
-//Deprecated: Warnf uses fmt.Sprintf to log a templated message.
+// Deprecated: Warnf uses fmt.Sprintf to log a templated message.
+// Use Sugared().Warnf instead.
 func (l *Logger) Warnf(format string, args ...interface{}) {
 	l.logf(zapcore.WarnLevel, format, args)
 }
 
-//Deprecated: Errorf uses fmt.Sprintf to log a templated message.
+// Deprecated: Errorf uses fmt.Sprintf to log a templated message.
+// Use Sugared().Errorf instead.
 func (l *Logger) Errorf(format string, args ...interface{}) {
 	l.logf(zapcore.ErrorLevel, format, args)
 }
 
-//Deprecated: DPanicf uses fmt.Sprintf to log a templated message. In development, the
+// Deprecated: DPanicf uses fmt.Sprintf to log a templated message. In development, the
 // logger then panics. (See DPanicLevel for details.)
+// Use Sugared().DPanicf instead.
 func (l *Logger) DPanicf(format string, args ...interface{}) {
 	l.logf(zapcore.DPanicLevel, format, args)
 }
 
-//Deprecated: Panicf uses fmt.Sprintf to log a templated message, then panics.
+// Deprecated: Panicf uses fmt.Sprintf to log a templated message, then panics.
+// Use Sugared().Panicf instead.
 func (l *Logger) Panicf(format string, args ...interface{}) {
 	l.logf(zapcore.PanicLevel, format, args)
 }
 
-//Deprecated: Fatalf uses fmt.Sprintf to log a templated message, then calls os.Exit.
+// Deprecated: Fatalf uses fmt.Sprintf to log a templated message, then calls os.Exit.
+// Use Sugared().Fatalf instead.
 func (l *Logger) Fatalf(format string, args ...interface{}) {
 	l.logf(zapcore.FatalLevel, format, args)
 }
@@ -181,49 +387,51 @@ func (l *Logger) Fatalf(format string, args ...interface{}) {
 // pairs are treated as they are in With.
 //
 // When debug-level logging is disabled, this is much faster than
-//  s.With(keysAndValues).Debug(msg)
+//
+//	s.With(keysAndValues).Debug(msg)
 func (l *Logger) Debugw(msg string, kv ...interface{}) {
-	l.logw(zapcore.DebugLevel, msg, kv)
+	l.logw(nil, zapcore.DebugLevel, msg, kv)
 }
 
 // Infow logs a message with some additional context. The variadic key-value
 // pairs are treated as they are in With.
 func (l *Logger) Infow(msg string, kv ...interface{}) {
-	l.logw(zapcore.InfoLevel, msg, kv)
+	l.logw(nil, zapcore.InfoLevel, msg, kv)
 }
 
 // Warnw logs a message with some additional context. The variadic key-value
 // pairs are treated as they are in With.
 func (l *Logger) Warnw(msg string, kv ...interface{}) {
-	l.logw(zapcore.WarnLevel, msg, kv)
+	l.logw(nil, zapcore.WarnLevel, msg, kv)
 }
 
 // Errorw logs a message with some additional context. The variadic key-value
 // pairs are treated as they are in With.
 func (l *Logger) Errorw(msg string, kv ...interface{}) {
-	l.logw(zapcore.ErrorLevel, msg, kv)
+	l.logw(nil, zapcore.ErrorLevel, msg, kv)
 }
 
 // DPanicw logs a message with some additional context. In development, the
 // logger then panics. (See zapcore.DPanicLevel for details.) The variadic key-value
 // pairs are treated as they are in With.
 func (l *Logger) DPanicw(msg string, kv ...interface{}) {
-	l.logw(zapcore.InfoLevel, msg, kv)
+	l.logw(nil, zapcore.DPanicLevel, msg, kv)
 }
 
 // Panicw logs a message with some additional context, then panics. The
 // variadic key-value pairs are treated as they are in With.
 func (l *Logger) Panicw(msg string, kv ...interface{}) {
-	l.logw(zapcore.PanicLevel, msg, kv)
+	l.logw(nil, zapcore.PanicLevel, msg, kv)
 }
 
 // Fatalw logs a message with some additional context, then calls os.Exit. The
 // variadic key-value pairs are treated as they are in With.
 func (l *Logger) Fatalw(msg string, kv ...interface{}) {
-	l.logw(zapcore.FatalLevel, msg, kv)
+	l.logw(nil, zapcore.FatalLevel, msg, kv)
 }
 
 func (l *Logger) logf(lvl zapcore.Level, format string, args []interface{}) {
+	lvl = l.safeLevel(lvl)
 	if lvl < zapcore.DPanicLevel && !l.base.Core().Enabled(lvl) {
 		return
 	}
@@ -235,59 +443,129 @@ func (l *Logger) logf(lvl zapcore.Level, format string, args []interface{}) {
 		}
 	}
 	if ce := l.base.Check(lvl, format); ce != nil {
-		ce.Write()
+		l.addStack(ce)
+		ce.Write(l.limit(nil)...)
 	}
 }
 
-func (l *Logger) logw(lvl zapcore.Level, msg string, kv []interface{}) {
+// logw logs kv. Unless ctx is nil, the trace fields of ctx are appended and
+// the entry may be recorded as a span event.
+func (l *Logger) logw(ctx context.Context, lvl zapcore.Level, msg string, kv []interface{}) {
+	lvl = l.safeLevel(lvl)
 	if lvl < zapcore.DPanicLevel && !l.base.Core().Enabled(lvl) {
 		return
 	}
 	if ce := l.base.Check(lvl, msg); ce != nil {
-		if n := len(kv); n > 0 {
-			fields, invalids := make([]zapcore.Field, 0, n), invalidPairs(nil)
-
-			for i, m := 0, n-1; i < n; {
-				if f, ok := kv[i].(zapcore.Field); ok {
-					fields = append(fields, f)
-					i++
-					continue
-				}
-
-				if ctx, ok := kv[i].(context.Context); ok {
-					i++
-
-					f := TraceId(ctx)
-					if f.String != NoTraceId {
-						fields = append(fields, TraceId(ctx))
-					}
-
-					continue
-				}
-
-				if i == m {
-					l.base.DPanic(danglingKeyErrMsg, zap.Any(`ignored`, kv[i]))
-					break
-				}
-				k, v := kv[i], kv[i+1]
-				if s, ok := k.(string); !ok {
-					if cap(invalids) == 0 {
-						invalids = make(invalidPairs, 0, n/2)
-					}
-					invalids = append(invalids, invalidPair{i, k, v})
-				} else {
-					fields = append(fields, zap.Any(s, v))
+		l.addStack(ce)
+		// The trace fields come from ctx if it's given, contexts among kv
+		// are only used without one so the trace ID is added once.
+		var trace []Field
+		if ctx != nil {
+			trace = l.traceFields(ctx, lvl)
+		}
+		mode := traceNone
+		if ctx == nil && lvl >= l.opts.traceMinLevel {
+			mode = l.opts.traceMode()
+		}
+		if len(kv) > 0 || len(trace) > 0 || ctx != nil && len(contextFields(ctx)) > 0 {
+			buf := getFields()
+			fields, invalids, dangling := sweeten(*buf, kv, mode)
+			if dangling >= 0 {
+				switch l.opts.danglingKey {
+				case DanglingKeyAsValue:
+					fields = append(fields, Any(danglingKeyName, kv[dangling]))
+				case DanglingKeyDrop:
+				default:
+					l.base.Log(l.safeLevel(zapcore.DPanicLevel), danglingKeyErrMsg, zap.Any(`ignored`, kv[dangling]))
 				}
-				i += 2
 			}
 			if len(invalids) > 0 {
-				l.base.DPanic(nonStringKeyErrMsg, zap.Array(`invalid`, invalids))
+				l.base.Log(l.safeLevel(zapcore.DPanicLevel), nonStringKeyErrMsg, zap.Array(`invalid`, invalids))
+			}
+			*buf = append(fields, trace...)
+			if ctx != nil {
+				*buf = appendContextFields(ctx, *buf)
+			}
+			fields = l.limit(l.opts.transform(*buf))
+			if ctx != nil {
+				l.addSpanEvent(ctx, lvl, msg, fields)
 			}
 			ce.Write(fields...)
+			if l.reusesFields() {
+				putFields(buf)
+			}
 		} else {
-			ce.Write()
+			if ctx != nil {
+				l.addSpanEvent(ctx, lvl, msg, nil)
+			}
+			ce.Write(l.limit(nil)...)
+		}
+	}
+}
+
+// fieldPool holds the slices logw builds the fields of an entry in. The
+// cores of this package don't keep the fields passed to Write, so the slices
+// can be reused once the entry is written, unless other cores may keep them,
+// see reusesFields.
+var fieldPool = sync.Pool{New: func() interface{} {
+	fields := make([]Field, 0, 16)
+	return &fields
+}}
+
+// maxPooledFields caps the capacity of the slices put back into fieldPool,
+// so that a rare huge entry doesn't pin its memory.
+const maxPooledFields = 256
+
+// reusesFields reports whether the slices of fields written by l can be
+// reused, which is the case if l has no cores from outside this package, such
+// as the ones added with WithTee, AlsoTo or WithOptions.
+func (l *Logger) reusesFields() bool {
+	return len(l.opts.tee) == 0 && len(l.also) == 0 && len(l.zapOpts) == 0
+}
+
+func getFields() *[]Field {
+	return fieldPool.Get().(*[]Field)
+}
+
+func putFields(fields *[]Field) {
+	if cap(*fields) > maxPooledFields {
+		return
+	}
+	for i := range *fields {
+		(*fields)[i] = Field{}
+	}
+	*fields = (*fields)[:0]
+	fieldPool.Put(fields)
+}
+
+// traceFields returns the trace fields of ctx. The trace and span IDs are
+// left out if the entry is below the configured trace fields level, the span
+// ID is only added when ctx carries a valid span, and with WithTraceSampling
+// neither is added unless the span is sampled. The correlation and request
+// IDs are always added.
+func (l *Logger) traceFields(ctx context.Context, lvl zapcore.Level) []Field {
+	fields := make([]Field, 0, 4)
+	if lvl >= l.opts.traceMinLevel && (!l.opts.traceSampledOnly || sampled(ctx)) {
+		fields = append(fields, TraceId(ctx))
+		if span := SpanId(ctx); span.String != NoTraceId {
+			fields = append(fields, span)
 		}
 	}
+	if id, ok := correlationIDFromContext(ctx); ok {
+		fields = append(fields, CorrelationID(id))
+	}
+	if id, ok := requestIDFromContext(ctx); ok {
+		fields = append(fields, RequestID(id))
+	}
+	return fields
+}
+
+// safeLevel caps lvl at ErrorLevel for Safe loggers.
+func (l *Logger) safeLevel(lvl zapcore.Level) zapcore.Level {
+	if l.safe && lvl > zapcore.ErrorLevel {
+		return zapcore.ErrorLevel
+	}
+	return lvl
 }
 
 type invalidPair struct {
@@ -312,11 +590,20 @@ func (ps invalidPairs) MarshalLogArray(enc zapcore.ArrayEncoder) error {
 	return err
 }
 
+// nopCloserSink adapts a WriteSyncer to zap.Sink without closing the
+// underlying writer. Wrap the writer with zapcore.Lock before passing it in:
+// the core writes each encoded entry with a single Write call, and the lock
+// keeps lines from interleaving when several goroutines log concurrently.
 type nopCloserSink struct{ zapcore.WriteSyncer }
 
+// stderr is shared by every logger writing to os.Stderr, so that the lock
+// covers entries from different loggers too.
+var stderr = nopCloserSink{newBrokenPipeSink(zapcore.Lock(consoleSink{os.Stderr}))}
+
 func (nopCloserSink) Close() error { return nil }
 
 const (
+	danglingKeyName    = `extra`
 	danglingKeyErrMsg  = `Ignored key without a value.`
 	nonStringKeyErrMsg = `Ignored key-value pairs with non-string keys.`
 )
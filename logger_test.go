@@ -0,0 +1,686 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// newBufferLogger returns a production logger writing JSON to a buffer.
+func newBufferLogger(opts ...Option) (*Logger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	return NewLogger(append([]Option{WithWriter(buf)}, opts...)...), buf
+}
+
+// newDevelopmentBufferLogger returns a development logger writing JSON, with
+// the development keys, to a buffer.
+func newDevelopmentBufferLogger(opts ...Option) (*Logger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	return NewDevelopmentLogger(append([]Option{WithWriter(buf), WithFormat(formatJSON)}, opts...)...), buf
+}
+
+// decodeLines decodes every line written to buf as a JSON object.
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if line == `` {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("line %q isn't a JSON object: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// decodeLine decodes the only line written to buf.
+func decodeLine(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+	entries := decodeLines(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1: %s", len(entries), buf)
+	}
+	return entries[0]
+}
+
+// testClock is a zapcore.Clock that only moves when told to.
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newTestClock() *testClock {
+	return &testClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) NewTicker(d time.Duration) *time.Ticker {
+	return time.NewTicker(d)
+}
+
+func (c *testClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// spanContext returns a context carrying a remote span, sampled or not.
+func spanContext(sampled bool) context.Context {
+	cfg := trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10},
+		SpanID:  trace.SpanID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08},
+		Remote:  true,
+	}
+	if sampled {
+		cfg.TraceFlags = trace.FlagsSampled
+	}
+	return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(cfg))
+}
+
+const (
+	testTraceID = `0102030405060708090a0b0c0d0e0f10`
+	testSpanID  = `0102030405060708`
+)
+
+func TestConcurrentLogging(t *testing.T) {
+	logger, buf := newBufferLogger()
+	// The derived loggers write to the same sink as their parent.
+	loggers := []*Logger{logger, logger.With(String(`child`, `with`)), logger.Named(`child`)}
+	const goroutines, perGoroutine = 16, 200
+	payload := strings.Repeat(`x`, 512)
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			l := loggers[g%len(loggers)]
+			for i := 0; i < perGoroutine; i++ {
+				l.Info(context.Background(), `concurrent`, `goroutine`, g, `i`, i, `payload`, payload)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	entries := decodeLines(t, buf)
+	if len(entries) != goroutines*perGoroutine {
+		t.Fatalf("got %d entries, want %d", len(entries), goroutines*perGoroutine)
+	}
+	for _, entry := range entries {
+		if entry[`payload`] != payload {
+			t.Fatalf("got a corrupted entry: %v", entry)
+		}
+	}
+}
+
+func TestTraceFieldsMinLevel(t *testing.T) {
+	logger, buf := newBufferLogger(WithTraceFieldsMinLevel(ErrorLevel))
+	ctx := WithRequestID(spanContext(true), `req`)
+
+	logger.Info(ctx, `info`)
+	logger.Error(ctx, `error`)
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if id, ok := entries[0][`traceId`]; ok {
+		t.Errorf("got traceId %v at info, want none", id)
+	}
+	if id, ok := entries[0][`spanId`]; ok {
+		t.Errorf("got spanId %v at info, want none", id)
+	}
+	if id := entries[0][`request_id`]; id != `req` {
+		t.Errorf("got request_id %v at info, want req", id)
+	}
+	if id := entries[1][`traceId`]; id != testTraceID {
+		t.Errorf("got traceId %v at error, want %s", id, testTraceID)
+	}
+	if id := entries[1][`spanId`]; id != testSpanID {
+		t.Errorf("got spanId %v at error, want %s", id, testSpanID)
+	}
+}
+
+func TestAlsoTo(t *testing.T) {
+	logger, buf := newBufferLogger()
+	audit, auditBuf := newBufferLogger()
+	audit.SetLevel(WarnLevel)
+	ctx := context.Background()
+
+	child := logger.With(String(`before`, `x`)).AlsoTo(audit).With(String(`after`, `y`))
+	child.Info(ctx, `info`)
+	child.Warn(ctx, `warn`)
+	// Clone rebuilds the core, which must keep the copy to audit.
+	child.Clone().Warn(ctx, `cloned`)
+
+	if entries := decodeLines(t, buf); len(entries) != 3 {
+		t.Errorf("got %d entries in the child's output, want 3", len(entries))
+	}
+	entries := decodeLines(t, auditBuf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries in the other output, want 2", len(entries))
+	}
+	for i, msg := range []string{`warn`, `cloned`} {
+		e := entries[i]
+		if e[`message`] != msg || e[`after`] != `y` || e[`before`] != nil {
+			t.Errorf("got %v, want message %s with only the fields attached after AlsoTo", e, msg)
+		}
+	}
+}
+
+func TestSafe(t *testing.T) {
+	logger, buf := newDevelopmentBufferLogger()
+	logger = logger.Safe()
+	ctx := context.Background()
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("a Safe logger panicked: %v", r)
+		}
+	}()
+	logger.DPanic(ctx, `dpanic`)
+	logger.Panic(ctx, `panic`)
+	logger.Panicw(`panicw`)
+	logger.Sugared().Panicf(`panicf`)
+	logger.Fatal(ctx, `fatal`)
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 5 {
+		t.Fatalf("got %d entries, want 5", len(entries))
+	}
+	for _, e := range entries {
+		if e[`L`] != `ERROR` {
+			t.Errorf("got %v logged at %v, want ERROR", e[`M`], e[`L`])
+		}
+	}
+}
+
+func TestWithSiblings(t *testing.T) {
+	logger, buf := newBufferLogger()
+	parent := logger.With(String(`parent`, `p`))
+	for i := 0; i < 10; i++ {
+		parent.With(Int(`i`, i)).Infow(`sibling`)
+	}
+	if len(parent.with) != 1 {
+		t.Errorf("got %d fields attached to the parent, want 1", len(parent.with))
+	}
+	entries := decodeLines(t, buf)
+	if len(entries) != 10 {
+		t.Fatalf("got %d entries, want 10", len(entries))
+	}
+	for i, e := range entries {
+		// The metadata are the level, time, caller and message.
+		if e[`i`] != float64(i) || e[`parent`] != `p` || len(e) != 6 {
+			t.Errorf("got %v, want only the parent field and i=%d", e, i)
+		}
+	}
+}
+
+func BenchmarkWith(b *testing.B) {
+	const n = 8
+	fields := make([]Field, n)
+	for i := range fields {
+		fields[i] = Int(`field`+strconv.Itoa(i), i)
+	}
+	logger := NewLogger(WithWriter(io.Discard))
+
+	b.Run(`each`, func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l := logger
+			for _, f := range fields {
+				l = l.With(f)
+			}
+		}
+	})
+	b.Run(`once`, func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.With(fields...)
+		}
+	})
+}
+
+func TestDanglingKeyPolicy(t *testing.T) {
+	for _, tt := range []struct {
+		policy  DanglingKeyPolicy
+		entries int
+		extra   interface{}
+	}{
+		{DanglingKeyDPanic, 2, nil},
+		{DanglingKeyAsValue, 1, `lone`},
+		{DanglingKeyDrop, 1, nil},
+	} {
+		logger, buf := newBufferLogger(WithDanglingKeyPolicy(tt.policy))
+		logger.Info(context.Background(), `odd`, `key`, `value`, `lone`)
+
+		entries := decodeLines(t, buf)
+		if len(entries) != tt.entries {
+			t.Fatalf("policy %d: got %d entries, want %d", tt.policy, len(entries), tt.entries)
+		}
+		if tt.policy == DanglingKeyDPanic {
+			if e := entries[0]; e[`level`] != `dpanic` || e[`message`] != danglingKeyErrMsg || e[`ignored`] != `lone` {
+				t.Errorf("policy %d: got %v, want a dpanic entry about the dangling key", tt.policy, e)
+			}
+		}
+		e := entries[len(entries)-1]
+		if e[`message`] != `odd` || e[`key`] != `value` || e[danglingKeyName] != tt.extra {
+			t.Errorf("policy %d: got %v, want %s=%v", tt.policy, e, danglingKeyName, tt.extra)
+		}
+	}
+}
+
+func TestDanglingKeyPanicsInDevelopment(t *testing.T) {
+	logger, _ := newDevelopmentBufferLogger()
+	defer func() {
+		if recover() == nil {
+			t.Error("a dangling key didn't panic in development")
+		}
+	}()
+	logger.Info(context.Background(), `odd`, `lone`)
+}
+
+func TestDPanicw(t *testing.T) {
+	logger, buf := newBufferLogger()
+	logger.DPanicw(`production`, `k`, `v`)
+	if e := decodeLine(t, buf); e[`level`] != `dpanic` || e[`k`] != `v` {
+		t.Errorf("got %v, want a dpanic entry", e)
+	}
+
+	dev, devBuf := newDevelopmentBufferLogger()
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("DPanicw didn't panic in development")
+			}
+		}()
+		dev.DPanicw(`development`, `k`, `v`)
+	}()
+	if e := decodeLine(t, devBuf); e[`L`] != `DPANIC` || e[`k`] != `v` {
+		t.Errorf("got %v, want a DPANIC entry written before the panic", e)
+	}
+}
+
+func TestWithIsolation(t *testing.T) {
+	parent, buf := newBufferLogger()
+	child := parent.With(String(`a`, `b`))
+	child.Infow(`child`)
+	parent.Infow(`parent`)
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0][`a`] != `b` {
+		t.Errorf("got %v from the child, want a=b", entries[0])
+	}
+	if a, ok := entries[1][`a`]; ok {
+		t.Errorf("got a=%v from the parent, want no a field", a)
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	logger := NewLogger(WithWriter(io.Discard))
+	if logger.Enabled(DebugLevel) || !logger.Enabled(InfoLevel) {
+		t.Error("got debug enabled or info disabled at the default level")
+	}
+	logger.SetLevel(DebugLevel)
+	if !logger.Enabled(DebugLevel) {
+		t.Error("got debug disabled after SetLevel(DebugLevel)")
+	}
+	logger.SetLevel(ErrorLevel)
+	if logger.Enabled(WarnLevel) || !logger.Enabled(ErrorLevel) {
+		t.Error("got warn enabled or error disabled after SetLevel(ErrorLevel)")
+	}
+}
+
+func TestClone(t *testing.T) {
+	logger, buf := newBufferLogger()
+	logger = logger.With(String(`service`, `api`))
+	clone := logger.Clone()
+
+	clone.SetLevel(DebugLevel)
+	logger.Debugw(`original debug`)
+	clone.Debugw(`clone debug`)
+	if e := decodeLine(t, buf); e[`message`] != `clone debug` || e[`service`] != `api` {
+		t.Errorf("got %v, want the clone's debug entry with the attached fields", e)
+	}
+	if logger.Level() != InfoLevel {
+		t.Errorf("got level %v for the original, want info", logger.Level())
+	}
+
+	logger.SetLevel(ErrorLevel)
+	if clone.Level() != DebugLevel {
+		t.Errorf("got level %v for the clone, want debug", clone.Level())
+	}
+	// With keeps sharing the level, unlike Clone.
+	if child := logger.With(String(`k`, `v`)); child.Level() != ErrorLevel {
+		t.Errorf("got level %v for a With child, want error", child.Level())
+	}
+}
+
+// TestNamedLevels reproduces the example, where a SIGUSR1 handler raising the
+// level of logger shouldn't change the level of logger2, named after it.
+func TestNamedLevels(t *testing.T) {
+	logger, buf := newBufferLogger()
+	logger2 := logger.Named(`logger2`)
+	ctx := context.Background()
+
+	logger.SetLevel(DebugLevel)
+	logger.Debug(ctx, `root debug`)
+	logger2.Debug(ctx, `named debug`)
+	logger2.SetLevel(ErrorLevel)
+	logger.Warn(ctx, `root warn`)
+	logger2.Warn(ctx, `named warn`)
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 || entries[0][`message`] != `root debug` || entries[1][`message`] != `root warn` {
+		t.Errorf("got %v, want only the root entries", entries)
+	}
+	if logger.Level() != DebugLevel || logger2.Level() != ErrorLevel {
+		t.Errorf("got levels %v and %v, want debug and error", logger.Level(), logger2.Level())
+	}
+	if child := logger.Named(`child`); child.Level() != DebugLevel {
+		t.Errorf("got level %v for a new named logger, want the parent's current debug", child.Level())
+	}
+}
+
+func TestNamedAlsoTo(t *testing.T) {
+	logger, buf := newBufferLogger()
+	audit, auditBuf := newBufferLogger()
+
+	named := logger.With(String(`before`, `x`)).AlsoTo(audit).With(String(`after`, `y`)).Named(`sub`)
+	named.Infow(`named`)
+
+	e := decodeLine(t, buf)
+	if e[`before`] != `x` || e[`after`] != `y` || e[`logger`] != `sub` {
+		t.Errorf("got %v, want both fields and the name", e)
+	}
+	e = decodeLine(t, auditBuf)
+	if e[`before`] != nil || e[`after`] != `y` || e[`logger`] != `sub` {
+		t.Errorf("got %v in the other output, want only the field attached after AlsoTo", e)
+	}
+}
+
+// logHere logs an entry and returns the line it was logged from.
+func logHere(l *Logger) int {
+	_, _, line, _ := runtime.Caller(0)
+	l.Infow(`here`)
+	return line + 1
+}
+
+func TestWithOptions(t *testing.T) {
+	logger, buf := newBufferLogger()
+	helperLine := logHere(logger)
+	_, _, line, _ := runtime.Caller(0)
+	logHere(logger.WithOptions(zap.AddCallerSkip(1)))
+	callerLine := line + 1
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	for i, want := range []int{helperLine, callerLine} {
+		if caller, _ := entries[i][`caller`].(string); !strings.HasSuffix(caller, `logger_test.go:`+strconv.Itoa(want)) {
+			t.Errorf("got caller %s, want line %d", caller, want)
+		}
+	}
+}
+
+func TestNopLogger(t *testing.T) {
+	logger := NewNopLogger()
+	if logger.Level() != InfoLevel || logger.Enabled(DebugLevel) || !logger.Enabled(InfoLevel) {
+		t.Errorf("got level %v, want info", logger.Level())
+	}
+	logger.SetLevel(DebugLevel)
+	if logger.Level() != DebugLevel || !logger.Enabled(DebugLevel) {
+		t.Errorf("got level %v after SetLevel(DebugLevel), want debug", logger.Level())
+	}
+	logger.SetLevel(ErrorLevel)
+	if logger.Enabled(WarnLevel) {
+		t.Error("got warn enabled after SetLevel(ErrorLevel)")
+	}
+	logger.Error(context.Background(), `nothing`, `k`, `v`)
+	if err := logger.Sync(); err != nil {
+		t.Errorf("got %v from Sync, want nil", err)
+	}
+}
+
+func TestSingleTraceId(t *testing.T) {
+	logger, buf := newBufferLogger()
+	logger.Info(spanContext(true), `both`, context.Background(), `k`, `v`)
+	if n := strings.Count(buf.String(), `"traceId"`); n != 1 {
+		t.Fatalf("got %d traceId fields in %q, want 1", n, buf)
+	}
+	if e := decodeLine(t, buf); e[`traceId`] != testTraceID || e[`k`] != `v` {
+		t.Errorf("got %v, want the trace ID of the ctx argument", e)
+	}
+
+	buf.Reset()
+	logger.Infow(`kv only`, spanContext(true), `k`, `v`)
+	if n := strings.Count(buf.String(), `"traceId"`); n != 1 {
+		t.Fatalf("got %d traceId fields in %q, want 1", n, buf)
+	}
+	if e := decodeLine(t, buf); e[`traceId`] != testTraceID {
+		t.Errorf("got %v, want the trace ID of the context among the arguments", e)
+	}
+}
+
+func TestNilContext(t *testing.T) {
+	if f := TraceId(nil); f.Key != `traceId` || f.String != NoTraceId {
+		t.Errorf("got %+v, want the unknown trace ID", f)
+	}
+	logger, buf := newBufferLogger()
+	logger.Info(nil, `msg`, `k`, `v`)
+	logger.Log(nil, WarnLevel, `msg`)
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	for _, e := range entries {
+		if e[`traceId`] != NoTraceId {
+			t.Errorf("got %v, want traceId %s", e, NoTraceId)
+		}
+	}
+}
+
+func TestZap(t *testing.T) {
+	logger, buf := newBufferLogger()
+	z := logger.With(String(`k`, `v`)).Zap()
+	if z.Core().Enabled(DebugLevel) {
+		t.Error("got debug enabled at the default level")
+	}
+	logger.SetLevel(DebugLevel)
+	if !z.Core().Enabled(DebugLevel) {
+		t.Error("got debug disabled after SetLevel(DebugLevel)")
+	}
+
+	_, _, line, _ := runtime.Caller(0)
+	z.Debug(`from zap`)
+	e := decodeLine(t, buf)
+	if e[`message`] != `from zap` || e[`k`] != `v` {
+		t.Errorf("got %v, want the entry with the attached field", e)
+	}
+	if caller, _ := e[`caller`].(string); !strings.HasSuffix(caller, `logger_test.go:`+strconv.Itoa(line+1)) {
+		t.Errorf("got caller %s, want line %d", caller, line+1)
+	}
+}
+
+func TestNamed(t *testing.T) {
+	root, buf := newBufferLogger()
+	if root.Named(``) != root {
+		t.Error("Named(``) didn't return the logger itself")
+	}
+	if root.Named(`a`) == root {
+		t.Error("Named(`a`) returned the logger itself")
+	}
+
+	for _, tt := range []struct {
+		name   string
+		logger *Logger
+		want   string
+		fields []string
+	}{
+		{`root`, root, ``, nil},
+		{`empty`, root.Named(``), ``, nil},
+		{`single`, root.Named(`a`), `a`, nil},
+		{`nested`, root.Named(`a`).Named(`b`), `a.b`, nil},
+		{`nested empty`, root.Named(`a`).Named(``).Named(`b`), `a.b`, nil},
+		{`with then named`, root.With(String(`x`, `1`)).Named(`a`), `a`, []string{`x`}},
+		{`named then with`, root.Named(`a`).With(String(`y`, `2`)), `a`, []string{`y`}},
+	} {
+		buf.Reset()
+		tt.logger.Infow(tt.name)
+		e := decodeLine(t, buf)
+		if name, _ := e[`logger`].(string); name != tt.want {
+			t.Errorf("%s: got name %q, want %q", tt.name, name, tt.want)
+		}
+		for _, key := range []string{`x`, `y`} {
+			want := false
+			for _, k := range tt.fields {
+				want = want || k == key
+			}
+			if _, ok := e[key]; ok != want {
+				t.Errorf("%s: got %v, want field %s only if attached on the way", tt.name, e, key)
+			}
+		}
+	}
+}
+
+// TestFieldPoolConcurrency checks that the pooled field slices aren't shared
+// between the entries logged concurrently.
+func TestFieldPoolConcurrency(t *testing.T) {
+	logger, buf := newBufferLogger(WithRedactedKeys(`secret`))
+	const goroutines, perGoroutine = 16, 200
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				id := strconv.Itoa(g) + `-` + strconv.Itoa(i)
+				if i%2 == 0 {
+					logger.Infow(`pooled`, `goroutine`, g, `i`, i, `id`, id, `secret`, id)
+				} else {
+					logger.Info(spanContext(true), `pooled`, `goroutine`, g, `i`, i, `id`, id)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	entries := decodeLines(t, buf)
+	if len(entries) != goroutines*perGoroutine {
+		t.Fatalf("got %d entries, want %d", len(entries), goroutines*perGoroutine)
+	}
+	for _, e := range entries {
+		g, _ := e[`goroutine`].(float64)
+		i, _ := e[`i`].(float64)
+		if want := strconv.Itoa(int(g)) + `-` + strconv.Itoa(int(i)); e[`id`] != want {
+			t.Fatalf("got %v, want id %s", e, want)
+		}
+		if int(i)%2 == 0 && e[`secret`] != redactedValue || int(i)%2 == 1 && e[`traceId`] != testTraceID {
+			t.Fatalf("got %v, want the fields of its own call", e)
+		}
+	}
+}
+
+// BenchmarkInfoFields measures a context-aware call with 4 key-value pairs,
+// whose fields are built in a pooled slice.
+func BenchmarkInfoFields(b *testing.B) {
+	logger := NewLogger(WithWriter(io.Discard))
+	ctx := spanContext(true)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.Info(ctx, `request served`, `method`, `GET`, `path`, `/users`, `status`, 200, `bytes`, 512)
+		}
+	})
+}
+
+func TestOnLevelChange(t *testing.T) {
+	logger := NewLogger(WithWriter(io.Discard))
+	type change struct{ old, new Level }
+	var first, second []change
+	logger.OnLevelChange(func(old, new Level) { first = append(first, change{old, new}) })
+	logger.With(String(`k`, `v`)).OnLevelChange(func(old, new Level) { second = append(second, change{old, new}) })
+
+	logger.SetLevel(DebugLevel)
+	logger.SetLevel(DebugLevel)
+	if err := logger.With(String(`k`, `v`)).SetLevelString(`error`); err != nil {
+		t.Fatal(err)
+	}
+	// Named loggers have their own level.
+	logger.Named(`child`).SetLevel(WarnLevel)
+
+	want := []change{{InfoLevel, DebugLevel}, {DebugLevel, ErrorLevel}}
+	if !reflect.DeepEqual(first, want) || !reflect.DeepEqual(second, want) {
+		t.Errorf("got %v and %v, want %v for both callbacks", first, second, want)
+	}
+
+	var module []change
+	logger.Module(`billing`).OnLevelChange(func(old, new Level) { module = append(module, change{old, new}) })
+	logger.SetLevelFor(`billing`, WarnLevel)
+	if want := []change{{ErrorLevel, WarnLevel}}; !reflect.DeepEqual(module, want) {
+		t.Errorf("got %v from the module's level, want %v", module, want)
+	}
+}
+
+func TestSetLevelWithoutListeners(t *testing.T) {
+	logger := NewLogger(WithWriter(io.Discard))
+	for i := 0; i < 10; i++ {
+		logger.Named(strconv.Itoa(i)).SetLevel(WarnLevel)
+	}
+	logger.SetLevel(DebugLevel)
+	if n := len(logger.modules.levelListeners); n != 0 {
+		t.Errorf("got %d levels with listeners, want none", n)
+	}
+	if logger.Level() != DebugLevel {
+		t.Errorf("got level %v, want debug", logger.Level())
+	}
+}
+
+func TestOnLevelChangeConcurrency(t *testing.T) {
+	logger := NewLogger(WithWriter(io.Discard))
+	var mu sync.Mutex
+	calls := 0
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			logger.OnLevelChange(func(old, new Level) {
+				mu.Lock()
+				calls++
+				mu.Unlock()
+			})
+			for i := 0; i < 100; i++ {
+				logger.SetLevel(Level(g%3 - 1))
+			}
+		}(g)
+	}
+	wg.Wait()
+	if calls == 0 {
+		t.Error("got no callback calls")
+	}
+}
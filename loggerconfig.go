@@ -0,0 +1,298 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoggerConfig is a serializable description of a Logger. Config captures it
+// from a logger and NewFromConfig builds a logger from it, which makes
+// file-driven construction and round-trip testing possible. Fields attached
+// with With aren't part of the configuration, and neither are the options
+// taking code or live values: WithTee, WithHook, WithClock,
+// WithStacktraceFilter and writers passed to WithWriter (see Output).
+type LoggerConfig struct {
+	// Level is the minimum enabled level. The zero value is InfoLevel.
+	Level Level `json:"level" yaml:"level"`
+	// ModuleLevels are the levels of modules, as set with SetLevelFor.
+	ModuleLevels map[string]Level `json:"moduleLevels,omitempty" yaml:"moduleLevels,omitempty"`
+	// NameLevels are the levels of logger names, as set with
+	// SetLevelForName.
+	NameLevels map[string]Level `json:"nameLevels,omitempty" yaml:"nameLevels,omitempty"`
+	// Development selects the console encoder and development behavior of
+	// NewDevelopmentLogger instead of the JSON production setup.
+	Development bool `json:"development,omitempty" yaml:"development,omitempty"`
+	// Format, if set, is the output format passed to WithFormat.
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+	// Keys, if set, renames the entry metadata keys, see WithEncoderKeys.
+	Keys *EncoderKeys `json:"keys,omitempty" yaml:"keys,omitempty"`
+	// Output, if set, is where the logger writes. It defaults to os.Stderr.
+	// Config leaves it unset for writers passed to WithWriter other than
+	// os.Stdout and os.Stderr, since they can't be described.
+	Output *OutputConfig `json:"output,omitempty" yaml:"output,omitempty"`
+	// StacktraceLevel, if set, is the level passed to WithStacktraceLevel.
+	StacktraceLevel *Level `json:"stacktraceLevel,omitempty" yaml:"stacktraceLevel,omitempty"`
+	// DisableCaller disables the caller field, see WithCaller.
+	DisableCaller bool `json:"disableCaller,omitempty" yaml:"disableCaller,omitempty"`
+	// CallerSkip is the number of frames passed to WithCallerSkip.
+	CallerSkip int `json:"callerSkip,omitempty" yaml:"callerSkip,omitempty"`
+	// Color, if set, is the setting passed to WithColor.
+	Color *bool `json:"color,omitempty" yaml:"color,omitempty"`
+	// TraceFieldsMinLevel, if set, is the level passed to
+	// WithTraceFieldsMinLevel.
+	TraceFieldsMinLevel *Level `json:"traceFieldsMinLevel,omitempty" yaml:"traceFieldsMinLevel,omitempty"`
+	// SpanEvents, if set, is the level passed to WithSpanEvents.
+	SpanEvents *Level `json:"spanEvents,omitempty" yaml:"spanEvents,omitempty"`
+	// TraceSampling enables WithTraceSampling.
+	TraceSampling bool `json:"traceSampling,omitempty" yaml:"traceSampling,omitempty"`
+	// OmitEmpty enables WithOmitEmpty.
+	OmitEmpty bool `json:"omitEmpty,omitempty" yaml:"omitEmpty,omitempty"`
+	// RedactedKeys are the keys passed to WithRedactedKeys.
+	RedactedKeys []string `json:"redactedKeys,omitempty" yaml:"redactedKeys,omitempty"`
+	// MaxFields, if positive, is the limit passed to WithMaxFields.
+	MaxFields int `json:"maxFields,omitempty" yaml:"maxFields,omitempty"`
+	// ProgressInterval, if positive, is the interval passed to
+	// WithProgressInterval.
+	ProgressInterval time.Duration `json:"progressInterval,omitempty" yaml:"progressInterval,omitempty"`
+	// SquelchRepeats enables WithSquelchRepeats.
+	SquelchRepeats bool `json:"squelchRepeats,omitempty" yaml:"squelchRepeats,omitempty"`
+	// DanglingKey is the policy passed to WithDanglingKeyPolicy.
+	DanglingKey DanglingKeyPolicy `json:"danglingKey,omitempty" yaml:"danglingKey,omitempty"`
+	// CancellationLogging enables WithCancellationLogging.
+	CancellationLogging bool `json:"cancellationLogging,omitempty" yaml:"cancellationLogging,omitempty"`
+	// Sampling, if set, enables WithSampling.
+	Sampling *SamplingConfig `json:"sampling,omitempty" yaml:"sampling,omitempty"`
+	// StartupBurst, if positive, is the duration passed to WithStartupBurst.
+	StartupBurst time.Duration `json:"startupBurst,omitempty" yaml:"startupBurst,omitempty"`
+	// ErrorWebhook, if set, enables WithErrorWebhook.
+	ErrorWebhook *WebhookConfig `json:"errorWebhook,omitempty" yaml:"errorWebhook,omitempty"`
+}
+
+// WebhookConfig describes the webhook of WithErrorWebhook.
+type WebhookConfig struct {
+	URL      string        `json:"url" yaml:"url"`
+	MinLevel Level         `json:"minLevel" yaml:"minLevel"`
+	Throttle time.Duration `json:"throttle" yaml:"throttle"`
+}
+
+// Config returns the configuration of the logger, with its current level.
+func (l *Logger) Config() LoggerConfig {
+	o := l.opts
+	cfg := LoggerConfig{
+		Level:               l.Level(),
+		Development:         o.development,
+		Format:              o.format,
+		DisableCaller:       o.noCaller,
+		CallerSkip:          o.callerSkip - baseCallerSkip,
+		TraceSampling:       o.traceSampledOnly,
+		OmitEmpty:           o.omitEmpty,
+		MaxFields:           o.maxFields,
+		DanglingKey:         o.danglingKey,
+		SquelchRepeats:      o.squelchRepeats,
+		StartupBurst:        o.startupBurst,
+		CancellationLogging: o.cancellationLogging,
+		ProgressInterval:    o.progressInterval,
+	}
+	if o.stacktraceLevel != defaultStacktraceLevel(o.development) {
+		level := o.stacktraceLevel
+		cfg.StacktraceLevel = &level
+	}
+	if o.color != nil {
+		color := *o.color
+		cfg.Color = &color
+	}
+	if o.keys != nil {
+		keys := *o.keys
+		cfg.Keys = &keys
+	}
+	cfg.Output = o.outputConfig()
+	cfg.ModuleLevels, cfg.NameLevels = l.modules.snapshot()
+	if o.traceMinLevel != allLevels {
+		level := o.traceMinLevel
+		cfg.TraceFieldsMinLevel = &level
+	}
+	if o.spanEvents {
+		level := o.spanEventLevel
+		cfg.SpanEvents = &level
+	}
+	for key := range o.redactedKeys {
+		cfg.RedactedKeys = append(cfg.RedactedKeys, key)
+	}
+	sort.Strings(cfg.RedactedKeys)
+	if o.sampling != nil {
+		sampling := *o.sampling
+		cfg.Sampling = &sampling
+	}
+	if o.webhook != nil {
+		cfg.ErrorWebhook = &WebhookConfig{
+			URL:      o.webhook.url,
+			MinLevel: o.webhook.minLevel,
+			Throttle: o.webhook.throttle,
+		}
+	}
+	return cfg
+}
+
+// NewFromConfig builds a logger from cfg. It returns an error if cfg holds
+// values that can't be applied.
+func NewFromConfig(cfg LoggerConfig) (*Logger, error) {
+	opts, err := cfg.options()
+	if err != nil {
+		return nil, err
+	}
+	var logger *Logger
+	if cfg.Development {
+		logger = NewDevelopmentLogger(opts...)
+	} else {
+		logger = NewLogger(opts...)
+	}
+	logger.SetLevel(cfg.Level)
+	for name, level := range cfg.ModuleLevels {
+		logger.SetLevelFor(name, level)
+	}
+	for name, level := range cfg.NameLevels {
+		logger.SetLevelForName(name, level)
+	}
+	return logger, nil
+}
+
+// NewFromJSON builds a logger from a JSON encoded LoggerConfig, e.g.
+//
+//	{"level": "debug", "maxFields": 64}
+//
+// Unknown keys and invalid values are reported as errors. Durations are
+// given in nanoseconds.
+func NewFromJSON(data []byte) (*Logger, error) {
+	var cfg LoggerConfig
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf(`log: invalid JSON config: %w`, err)
+	}
+	return NewFromConfig(cfg)
+}
+
+// NewFromYAML builds a logger from a YAML encoded LoggerConfig, e.g.
+//
+//	level: debug
+//	progressInterval: 30s
+//	keys:
+//	  message: msg
+//	output:
+//	  type: file
+//	  path: /var/log/app.log
+//	  maxSizeMB: 100
+//
+// Unknown keys and invalid values are reported as errors. An empty document
+// yields the default configuration.
+func NewFromYAML(data []byte) (*Logger, error) {
+	var cfg LoggerConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf(`log: invalid YAML config: %w`, err)
+	}
+	return NewFromConfig(cfg)
+}
+
+func (cfg LoggerConfig) options() ([]Option, error) {
+	var opts []Option
+	if cfg.Format != `` {
+		if !validFormat(cfg.Format) {
+			return nil, fmt.Errorf(`log: invalid format %q, want json, console, logfmt or gelf`, cfg.Format)
+		}
+		opts = append(opts, WithFormat(cfg.Format))
+	}
+	if cfg.Keys != nil {
+		opts = append(opts, WithEncoderKeys(*cfg.Keys))
+	}
+	if cfg.Output != nil {
+		out, err := cfg.Output.options()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, out...)
+	}
+	if cfg.StacktraceLevel != nil {
+		opts = append(opts, WithStacktraceLevel(*cfg.StacktraceLevel))
+	}
+	if cfg.DisableCaller {
+		opts = append(opts, WithCaller(false))
+	}
+	if cfg.CallerSkip != 0 {
+		opts = append(opts, WithCallerSkip(cfg.CallerSkip))
+	}
+	if cfg.Color != nil {
+		opts = append(opts, WithColor(*cfg.Color))
+	}
+	if cfg.TraceFieldsMinLevel != nil {
+		opts = append(opts, WithTraceFieldsMinLevel(*cfg.TraceFieldsMinLevel))
+	}
+	if cfg.SpanEvents != nil {
+		opts = append(opts, WithSpanEvents(*cfg.SpanEvents))
+	}
+	if cfg.TraceSampling {
+		opts = append(opts, WithTraceSampling())
+	}
+	if cfg.OmitEmpty {
+		opts = append(opts, WithOmitEmpty())
+	}
+	if len(cfg.RedactedKeys) > 0 {
+		opts = append(opts, WithRedactedKeys(cfg.RedactedKeys...))
+	}
+	if cfg.MaxFields < 0 {
+		return nil, fmt.Errorf(`log: maxFields must not be negative, got %d`, cfg.MaxFields)
+	}
+	if cfg.MaxFields > 0 {
+		opts = append(opts, WithMaxFields(cfg.MaxFields))
+	}
+	if _, err := cfg.DanglingKey.MarshalText(); err != nil {
+		return nil, err
+	}
+	opts = append(opts, WithDanglingKeyPolicy(cfg.DanglingKey))
+	if cfg.SquelchRepeats {
+		opts = append(opts, WithSquelchRepeats())
+	}
+	if cfg.ProgressInterval < 0 {
+		return nil, fmt.Errorf(`log: progressInterval must not be negative, got %s`, cfg.ProgressInterval)
+	}
+	if cfg.ProgressInterval > 0 {
+		opts = append(opts, WithProgressInterval(cfg.ProgressInterval))
+	}
+	if cfg.CancellationLogging {
+		opts = append(opts, WithCancellationLogging())
+	}
+	if s := cfg.Sampling; s != nil {
+		if s.First < 0 || s.Thereafter < 0 {
+			return nil, fmt.Errorf(`log: sampling.first and sampling.thereafter must not be negative, got %d and %d`, s.First, s.Thereafter)
+		}
+		opts = append(opts, WithSampling(s.First, s.Thereafter))
+	}
+	if cfg.StartupBurst < 0 {
+		return nil, fmt.Errorf(`log: startupBurst must not be negative, got %s`, cfg.StartupBurst)
+	}
+	if cfg.StartupBurst > 0 {
+		opts = append(opts, WithStartupBurst(cfg.StartupBurst))
+	}
+	if wh := cfg.ErrorWebhook; wh != nil {
+		if wh.URL == `` {
+			return nil, errors.New(`log: errorWebhook.url must not be empty`)
+		}
+		if _, err := url.ParseRequestURI(wh.URL); err != nil {
+			return nil, fmt.Errorf(`log: invalid errorWebhook.url: %w`, err)
+		}
+		if wh.Throttle < 0 {
+			return nil, fmt.Errorf(`log: errorWebhook.throttle must not be negative, got %s`, wh.Throttle)
+		}
+		opts = append(opts, WithErrorWebhook(wh.URL, wh.MinLevel, wh.Throttle))
+	}
+	return opts, nil
+}
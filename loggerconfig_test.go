@@ -0,0 +1,156 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `app.log`)
+	logger := NewLogger(
+		WithFormat(formatLogfmt),
+		WithEncoderKeys(EncoderKeys{Message: `msg`}),
+		WithRotatingFile(path, 10, 3, 7),
+		WithBufferedWriter(time.Second, 4096),
+		WithStacktraceLevel(DPanicLevel),
+		WithCaller(false),
+		WithCallerSkip(1),
+		WithColor(false),
+		WithSpanEvents(ErrorLevel),
+		WithTraceFieldsMinLevel(WarnLevel),
+		WithTraceSampling(),
+		WithOmitEmpty(),
+		WithRedactedKeys(`token`, `password`),
+		WithMaxFields(32),
+		WithProgressInterval(time.Minute),
+		WithSquelchRepeats(),
+		WithDanglingKeyPolicy(DanglingKeyAsValue),
+		WithCancellationLogging(),
+		WithSampling(10, 100),
+		WithStartupBurst(5*time.Second),
+	)
+	defer logger.Close()
+	logger.SetLevel(DebugLevel)
+	logger.SetLevelFor(`billing`, ErrorLevel)
+	logger.SetLevelForName(`api.auth`, WarnLevel)
+
+	cfg := logger.Config()
+	rebuilt, err := NewFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rebuilt.Close()
+	if got := rebuilt.Config(); !reflect.DeepEqual(got, cfg) {
+		t.Errorf("got config %+v after the round trip, want %+v", got, cfg)
+	}
+}
+
+func TestConfigDefaults(t *testing.T) {
+	cfg := NewLogger().Config()
+	want := LoggerConfig{
+		Level:            InfoLevel,
+		Format:           formatJSON,
+		ProgressInterval: defaultProgressInterval,
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+}
+
+func TestNewFromYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `app.log`)
+	logger, err := NewFromYAML([]byte(fmt.Sprintf(`
+level: debug
+keys:
+  message: msg
+output:
+  type: file
+  path: %s
+  maxSizeMB: 100
+redactedKeys: [token]
+progressInterval: 30s
+sampling:
+  first: 10
+  thereafter: 100
+moduleLevels:
+  billing: error
+`, path)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	cfg := logger.Config()
+	if cfg.Level != DebugLevel || cfg.ProgressInterval != 30*time.Second || cfg.ModuleLevels[`billing`] != ErrorLevel ||
+		cfg.Output == nil || cfg.Output.Path != path || cfg.Output.MaxSizeMB != 100 ||
+		cfg.Sampling == nil || *cfg.Sampling != (SamplingConfig{First: 10, Thereafter: 100}) {
+		t.Errorf("got config %+v", cfg)
+	}
+
+	logger.Debug(context.Background(), `from yaml`, `token`, `secret`)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry[`msg`] != `from yaml` || entry[`token`] != redactedValue {
+		t.Errorf("got entry %v", entry)
+	}
+}
+
+func TestNewFromJSON(t *testing.T) {
+	logger, err := NewFromJSON([]byte(`{"level": "warn", "format": "logfmt", "maxFields": 64, "danglingKey": "drop"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := logger.Config()
+	if cfg.Level != WarnLevel || cfg.Format != formatLogfmt || cfg.MaxFields != 64 || cfg.DanglingKey != DanglingKeyDrop {
+		t.Errorf("got config %+v", cfg)
+	}
+}
+
+func TestNewFromYAMLEmpty(t *testing.T) {
+	logger, err := NewFromYAML(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := logger.Config(), NewLogger().Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got config %+v, want the default %+v", got, want)
+	}
+}
+
+func TestNewFromConfigErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name, yaml, err string
+	}{
+		{`unknown key`, `colour: true`, `field colour not found`},
+		{`bad level`, `level: loud`, `unrecognized level`},
+		{`bad format`, `format: xml`, `invalid format`},
+		{`bad output`, `output: {type: tape}`, `invalid output.type`},
+		{`file without path`, `output: {type: file}`, `output.path`},
+		{`negative max fields`, `maxFields: -1`, `maxFields`},
+		{`bad dangling key policy`, `danglingKey: ignore`, `dangling key policy`},
+		{`negative sampling`, `sampling: {first: -1}`, `sampling`},
+		{`bad webhook`, `errorWebhook: {url: "not a url"}`, `errorWebhook.url`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFromYAML([]byte(tt.yaml))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("got error %v, want one containing %q", err, tt.err)
+			}
+		})
+	}
+	if _, err := NewFromJSON([]byte(`{"colour": true}`)); err == nil || !strings.Contains(err.Error(), `unknown field`) {
+		t.Errorf("got error %v from NewFromJSON, want an unknown field error", err)
+	}
+}
@@ -0,0 +1,22 @@
+package logtest_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vsjadeja/log"
+	"github.com/vsjadeja/log/logtest"
+)
+
+func ExampleNewObserver() {
+	logger, logs := logtest.NewObserver(log.InfoLevel)
+	logger.Debug(context.Background(), `work started`)
+	logger.Info(context.Background(), `work done`, `count`, 3)
+
+	entries := logs.FilterMessage(`work done`).All()
+	fmt.Println(len(entries), entries[0].ContextMap()[`count`])
+	fmt.Println(logs.FilterField(log.Int(`count`, 3)).Len(), logs.Len())
+	// Output:
+	// 1 3
+	// 1 1
+}
@@ -0,0 +1,49 @@
+// Package logtest helps testing code that logs through a log.Logger by
+// capturing the entries it writes:
+//
+//	logger, logs := logtest.NewObserver(log.DebugLevel)
+//	doWork(logger)
+//	entries := logs.FilterMessage(`work done`).All()
+//	if len(entries) != 1 || entries[0].ContextMap()[`count`] != int64(3) {
+//		t.Errorf(`unexpected entries: %v`, entries)
+//	}
+package logtest
+
+import (
+	"testing"
+
+	"github.com/vsjadeja/log"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// ObservedLogs holds the entries captured by an observer. All returns them,
+// and FilterMessage and FilterField select the ones with a message or a
+// field.
+type ObservedLogs = observer.ObservedLogs
+
+// NewObserver returns a logger at the given level that records its entries
+// in memory instead of writing them out. Entries are recorded with the fields
+// added by With and the trace fields, like they'd be encoded, and SetLevel on
+// the logger applies.
+func NewObserver(level log.Level) (*log.Logger, *ObservedLogs) {
+	core, logs := observer.New(zapcore.LevelEnabler(enabled{}))
+	logger := log.NewLogger(log.WithWriter(nil), log.WithTee(core))
+	logger.SetLevel(level)
+	return logger, logs
+}
+
+// enabled enables every level, the logger's level filters the entries.
+type enabled struct{}
+
+func (enabled) Enabled(log.Level) bool { return true }
+
+// NewTestLogger returns a development logger at DebugLevel that writes
+// through tb.Log, so the output of a test is shown only when it fails or runs
+// verbosely, and is attributed to the test. Entries logged after the test
+// completes make it fail.
+func NewTestLogger(tb testing.TB, opts ...log.Option) *log.Logger {
+	opts = append(opts[:len(opts):len(opts)], log.WithWriter(zaptest.NewTestingWriter(tb)))
+	return log.NewDevelopmentLogger(opts...)
+}
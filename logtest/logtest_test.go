@@ -0,0 +1,60 @@
+package logtest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vsjadeja/log"
+)
+
+func TestNewObserver(t *testing.T) {
+	logger, logs := NewObserver(log.WarnLevel)
+	ctx := context.Background()
+	logger.Info(ctx, `hidden`)
+	logger.With(log.String(`user`, `alice`)).Warn(ctx, `shown`)
+	logger.SetLevel(log.InfoLevel)
+	logger.Info(ctx, `info`)
+
+	entries := logs.All()
+	if len(entries) != 2 || entries[0].Message != `shown` || entries[1].Message != `info` {
+		t.Fatalf("got %v, want the warning and the info entry", entries)
+	}
+	fields := entries[0].ContextMap()
+	if fields[`user`] != `alice` || fields[`traceId`] != log.NoTraceId {
+		t.Errorf("got fields %v, want the attached field and the trace ID", fields)
+	}
+}
+
+// recordingTB records the lines logged to it.
+type recordingTB struct {
+	testing.TB
+	lines []string
+}
+
+func (tb *recordingTB) Logf(format string, args ...interface{}) {
+	tb.lines = append(tb.lines, fmt.Sprintf(format, args...))
+}
+
+func TestNewTestLogger(t *testing.T) {
+	tb := &recordingTB{TB: t}
+	logger := NewTestLogger(tb)
+	ctx := context.Background()
+	logger.Debug(ctx, `debug`, `k`, `v`)
+	logger.SetLevel(log.WarnLevel)
+	logger.Info(ctx, `hidden`)
+	logger.Warn(ctx, `warn`)
+
+	if len(tb.lines) != 2 {
+		t.Fatalf("got lines %q, want 2", tb.lines)
+	}
+	for i, want := range []string{`DEBUG`, `WARN`} {
+		if line := tb.lines[i]; !strings.Contains(line, want) || strings.HasSuffix(line, "\n") {
+			t.Errorf("got %q, want a %s line without the trailing newline", line, want)
+		}
+	}
+	if !strings.Contains(tb.lines[0], `"k": "v"`) {
+		t.Errorf("got %q, want the fields", tb.lines[0])
+	}
+}
@@ -0,0 +1,69 @@
+package log
+
+import (
+	"net/http"
+)
+
+const requestMsg = `http request`
+
+// Middleware returns HTTP middleware that logs every request at info level
+// once it's served, with the method, path, status, bytes written and latency.
+// The trace fields come from the request context. Handlers can retrieve a
+// request-scoped logger, derived from l with the method and path attached,
+// with FromContext(r.Context()).
+func Middleware(l *Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := l.opts.clock.Now()
+			ctx := r.Context()
+			stop := l.watchCancel(ctx)
+			defer stop()
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			scoped := l.With(Method(r.Method), String(`path`, r.URL.Path))
+			next.ServeHTTP(sw, r.WithContext(WithContext(ctx, scoped)))
+			l.Info(ctx, requestMsg,
+				Method(r.Method),
+				String(`path`, r.URL.Path),
+				Status(sw.status),
+				Int64(`bytes`, sw.bytes),
+				Latency(l.opts.clock.Now().Sub(start)),
+			)
+		})
+	}
+}
+
+// statusWriter records the status code and the number of bytes written.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = code, true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush sends the buffered data to the client if the underlying writer
+// supports it, so streaming handlers keep working behind the middleware.
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,92 @@
+package log
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMiddleware(t *testing.T) {
+	logger, buf := newBufferLogger()
+	handler := Middleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Info(r.Context(), `handling`)
+		switch r.URL.Path {
+		case `/missing`:
+			w.WriteHeader(http.StatusNotFound)
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.Write([]byte(`hello`))
+		}
+	}))
+
+	for _, path := range []string{`/hello`, `/missing`} {
+		req := httptest.NewRequest(http.MethodGet, path, nil).WithContext(spanContext(true))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 4 {
+		t.Fatalf("got %d entries, want a scoped entry and a request entry per request", len(entries))
+	}
+	for i, tt := range []struct {
+		path   string
+		status float64
+		class  string
+		bytes  float64
+	}{
+		{`/hello`, 200, `2xx`, 5},
+		{`/missing`, 404, `4xx`, 0},
+	} {
+		scoped, e := entries[2*i], entries[2*i+1]
+		if scoped[`message`] != `handling` || scoped[`method`] != http.MethodGet || scoped[`path`] != tt.path {
+			t.Errorf("got %v, want the request-scoped logger's entry", scoped)
+		}
+		if e[`message`] != requestMsg || e[`level`] != `info` || e[`method`] != http.MethodGet || e[`path`] != tt.path {
+			t.Errorf("got %v, want an info entry for %s", e, tt.path)
+		}
+		if e[`status`] != tt.status || e[`status_class`] != tt.class || e[`bytes`] != tt.bytes {
+			t.Errorf("got %v, want status %v and %v bytes", e, tt.status, tt.bytes)
+		}
+		if e[`traceId`] != testTraceID {
+			t.Errorf("got traceId %v, want %s", e[`traceId`], testTraceID)
+		}
+		if _, ok := e[`latency_ms`].(float64); !ok {
+			t.Errorf("got %v, want the latency", e)
+		}
+	}
+}
+
+func TestMiddlewareWithoutSpan(t *testing.T) {
+	logger, buf := newBufferLogger()
+	handler := Middleware(logger)(http.NotFoundHandler())
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, `/`, nil).WithContext(context.Background()))
+
+	if e := decodeLine(t, buf); e[`traceId`] != NoTraceId || e[`status`] != 404.0 || e[`method`] != http.MethodPost {
+		t.Errorf("got %v, want a 404 POST without a trace ID", e)
+	}
+}
+
+func TestMiddlewareFlushAndLatency(t *testing.T) {
+	clock := newTestClock()
+	logger, buf := newBufferLogger(WithClock(clock))
+	handler := Middleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`chunk`))
+		f, ok := w.(http.Flusher)
+		if !ok {
+			t.Fatal("got a writer without Flush, want an http.Flusher")
+		}
+		f.Flush()
+		clock.Add(1500 * time.Millisecond)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, `/stream`, nil))
+	if !rec.Flushed {
+		t.Error("got the response unflushed, want Flush passed through")
+	}
+	if e := decodeLine(t, buf); e[`latency_ms`] != 1500.0 {
+		t.Errorf("got %v, want a latency of 1500ms from the logger's clock", e)
+	}
+}
@@ -0,0 +1,101 @@
+package log
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// moduleLevels holds the levels of the loggers created with Module, the
+// overrides set with SetLevelForName and the OnLevelChange callbacks of every
+// level. It's shared by a root logger and everything derived from it.
+type moduleLevels struct {
+	mu             sync.Mutex
+	levels         map[string]zap.AtomicLevel
+	names          map[string]zap.AtomicLevel
+	levelListeners map[zap.AtomicLevel]*levelListeners
+}
+
+func newModuleLevels() *moduleLevels {
+	return &moduleLevels{
+		levels:         map[string]zap.AtomicLevel{},
+		names:          map[string]zap.AtomicLevel{},
+		levelListeners: map[zap.AtomicLevel]*levelListeners{},
+	}
+}
+
+// get returns the level of the module, registering it at def if it's new.
+func (m *moduleLevels) get(name string, def Level) zap.AtomicLevel {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	level, ok := m.levels[name]
+	if !ok {
+		level = zap.NewAtomicLevelAt(def)
+		m.levels[name] = level
+	}
+	return level
+}
+
+// Module creates a named child logger for a part of the application, such as
+// "auth" or "billing", with its own level. The child shares the output and
+// format of l, and its level starts at l's current level unless it was set
+// with SetLevelFor before. Every call to Module with the same name on loggers
+// derived from the same root shares the level.
+func (l *Logger) Module(name string) *Logger {
+	return l.named(name).rebuild(l.modules.get(name, l.Level()))
+}
+
+// SetLevelFor alters the level of the module with the given name. If no
+// logger has been created for the module yet, the level applies to the
+// loggers created by Module later.
+func (l *Logger) SetLevelFor(name string, level Level) {
+	l.modules.setLevel(l.modules.get(name, level), level)
+}
+
+// name returns the override of the logger name, if any.
+func (m *moduleLevels) name(name string) (zap.AtomicLevel, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	level, ok := m.names[name]
+	return level, ok
+}
+
+// setName sets the override of the logger name.
+func (m *moduleLevels) setName(name string, level Level) {
+	m.mu.Lock()
+	l, ok := m.names[name]
+	if !ok {
+		m.names[name] = zap.NewAtomicLevelAt(level)
+	}
+	m.mu.Unlock()
+	if ok {
+		m.setLevel(l, level)
+	}
+}
+
+// SetLevelForName gives the loggers with the given full name, as built by
+// Named (e.g. "main.subordinate"), their own level, independent of the level
+// of their parent. Loggers that Named creates after the call use the
+// override, and so do loggers linked to it by an earlier call.
+func (l *Logger) SetLevelForName(name string, level Level) {
+	l.modules.setName(name, level)
+}
+
+// snapshot returns the current levels of the modules and of the name
+// overrides, or nil maps if there are none.
+func (m *moduleLevels) snapshot() (modules, names map[string]Level) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return levelMap(m.levels), levelMap(m.names)
+}
+
+func levelMap(levels map[string]zap.AtomicLevel) map[string]Level {
+	if len(levels) == 0 {
+		return nil
+	}
+	out := make(map[string]Level, len(levels))
+	for name, level := range levels {
+		out[name] = level.Level()
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package log
+
+import (
+	"context"
+	"testing"
+)
+
+func TestModule(t *testing.T) {
+	logger, buf := newBufferLogger()
+	ctx := context.Background()
+	// The level set before the module exists applies once it's created.
+	logger.SetLevelFor(`billing`, ErrorLevel)
+	auth := logger.Module(`auth`)
+	billing := logger.Module(`billing`)
+
+	logger.SetLevelFor(`auth`, DebugLevel)
+	auth.Debug(ctx, `auth debug`)
+	billing.Warn(ctx, `billing warn`)
+	billing.Error(ctx, `billing error`)
+	logger.Debug(ctx, `root debug`)
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	for i, want := range []struct{ logger, message string }{
+		{`auth`, `auth debug`},
+		{`billing`, `billing error`},
+	} {
+		if entries[i][`logger`] != want.logger || entries[i][`message`] != want.message {
+			t.Errorf("got %v, want %s from %s", entries[i], want.message, want.logger)
+		}
+	}
+
+	// Every Module call with the same name shares the level.
+	if again := logger.With(String(`k`, `v`)).Module(`billing`); again.Level() != ErrorLevel {
+		t.Errorf("got level %v for billing again, want error", again.Level())
+	}
+	billing.SetLevel(InfoLevel)
+	if level := logger.Module(`billing`).Level(); level != InfoLevel {
+		t.Errorf("got level %v for billing after SetLevel, want info", level)
+	}
+	if logger.Level() != InfoLevel || auth.Level() != DebugLevel {
+		t.Errorf("got levels %v and %v for the root and auth, want info and debug", logger.Level(), auth.Level())
+	}
+}
+
+func TestSetLevelForName(t *testing.T) {
+	logger, buf := newBufferLogger()
+	ctx := context.Background()
+	logger.SetLevelForName(`main.subordinate`, DebugLevel)
+	main := logger.Named(`main`)
+	sub := main.Named(`subordinate`)
+
+	main.Debug(ctx, `main debug`)
+	sub.Debug(ctx, `subordinate debug`)
+	main.Info(ctx, `main info`)
+	if e := decodeLines(t, buf); len(e) != 2 || e[0][`message`] != `subordinate debug` || e[1][`message`] != `main info` {
+		t.Errorf("got %v, want the subordinate debug and the main info entries", e)
+	}
+
+	// A later call changes the level of the loggers already linked to it.
+	logger.SetLevelForName(`main.subordinate`, ErrorLevel)
+	if sub.Level() != ErrorLevel || main.Level() != InfoLevel {
+		t.Errorf("got levels %v and %v for subordinate and main, want error and info", sub.Level(), main.Level())
+	}
+	if again := logger.Named(`main`).Named(`subordinate`); again.Level() != ErrorLevel {
+		t.Errorf("got level %v for a new subordinate logger, want error", again.Level())
+	}
+	if other := logger.Named(`subordinate`); other.Level() != InfoLevel {
+		t.Errorf("got level %v for a logger with a different full name, want info", other.Level())
+	}
+}
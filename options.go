@@ -0,0 +1,361 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Output formats.
+const (
+	formatJSON    = `json`
+	formatConsole = `console`
+	formatLogfmt  = `logfmt`
+)
+
+// validFormat reports whether format is one of the output formats.
+func validFormat(format string) bool {
+	switch format {
+	case formatJSON, formatConsole, formatLogfmt, formatGELF:
+		return true
+	}
+	return false
+}
+
+// allLevels is below every built-in and custom level.
+const allLevels = Level(math.MinInt8)
+
+// An Option configures a Logger built by NewLogger or NewDevelopmentLogger.
+type Option func(*options)
+
+type options struct {
+	development     bool
+	format          string
+	encoderConfig   zapcore.EncoderConfig
+	sink            zapcore.WriteSyncer
+	errSink         zapcore.WriteSyncer
+	syslogTag       string
+	buffer          *bufferConfig
+	tee             []zapcore.Core
+	terminal        bool
+	color           *bool
+	stacktraceLevel Level
+	stackFilter     func(frame runtime.Frame) bool
+	callerSkip      int
+	noCaller        bool
+	traceMinLevel   Level
+	webhook         *webhookConfig
+	omitEmpty       bool
+	redactedKeys    map[string]struct{}
+	maxFields       int
+	danglingKey     DanglingKeyPolicy
+	squelchRepeats  bool
+	spanEvents      bool
+	spanEventLevel  Level
+	// traceSampledOnly is set by WithTraceSampling.
+	traceSampledOnly bool
+	// cancellationLogging is set by WithCancellationLogging.
+	cancellationLogging bool
+	clock               zapcore.Clock
+	startupBurst        time.Duration
+	sampling            *SamplingConfig
+	hooks               []func(zapcore.Entry) error
+	// keys is set by WithEncoderKeys.
+	keys *EncoderKeys
+
+	progressInterval time.Duration
+
+	// writer is the writer sink ends in when it comes from WithWriter or
+	// WithRotatingFile, before the options wrapped it.
+	writer io.Writer
+
+	// webhookCore is built once from webhook and shared by every core built
+	// from these options.
+	webhookCore zapcore.Core
+	// burstUntil ends the startup burst.
+	burstUntil time.Time
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+func newOptions(development bool, opts []Option) *options {
+	o := &options{
+		development:      development,
+		format:           formatJSON,
+		encoderConfig:    productionEncoderConfig,
+		sink:             stderr,
+		terminal:         isTerminal(os.Stderr),
+		stacktraceLevel:  defaultStacktraceLevel(development),
+		callerSkip:       baseCallerSkip,
+		traceMinLevel:    allLevels,
+		progressInterval: defaultProgressInterval,
+		clock:            zapcore.DefaultClock,
+	}
+	if development {
+		o.format = formatConsole
+		o.encoderConfig = developmentEncoderConfig
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	o.sink = o.buffer.buffered(o.sink)
+	o.errSink = o.buffer.buffered(o.errSink)
+	if development && o.colorLevels() {
+		o.encoderConfig = colorEncoderConfig(o.encoderConfig)
+	}
+	if o.webhook != nil {
+		o.webhookCore = newWebhookCore(*o.webhook)
+	}
+	if f, ok := o.writer.(*rotatingFile); ok {
+		f.clock = o.clock
+	}
+	o.burstUntil = o.clock.Now().Add(o.startupBurst)
+	return o
+}
+
+// WithWriter makes the logger write to w instead of os.Stderr, e.g. a file, a
+// pipe or an in-memory buffer in tests. Writes are serialized, so w doesn't
+// need to be safe for concurrent use. A nil w writes nothing and doesn't
+// encode entries at all; only the cores of WithTee, the hooks and the webhook
+// still see them.
+func WithWriter(w io.Writer) Option {
+	return func(o *options) {
+		if w == nil {
+			o.sink, o.writer, o.errSink, o.terminal = nil, nil, nil, false
+			return
+		}
+		ws := zapcore.AddSync(w)
+		if isConsole(w) {
+			ws = consoleSink{ws}
+		}
+		o.sink = nopCloserSink{newBrokenPipeSink(zapcore.Lock(ws))}
+		o.writer = w
+		o.errSink = nil
+		o.terminal = isTerminal(w)
+	}
+}
+
+// WithFormat sets the output format: "json", the default of NewLogger,
+// "console", the default of NewDevelopmentLogger, "logfmt", space separated
+// key=value pairs, or "gelf", GELF 1.1 documents for Graylog with the fields
+// as flat additional fields and the level as a syslog severity. Any other
+// format is written as JSON.
+func WithFormat(format string) Option {
+	return func(o *options) {
+		o.format = format
+	}
+}
+
+// baseCallerSkip is the number of frames between the caller of a logging
+// method and the zap logger: the method itself and logw, logf or logBatch.
+const baseCallerSkip = 2
+
+// WithCallerSkip increases the number of frames skipped to find the caller by
+// n, for helpers that wrap the logging methods, so that the caller is the
+// call site of the helper rather than the helper itself. The logger already
+// skips its own frames, a base of 2, so a helper that calls Info directly
+// needs WithCallerSkip(1). Like zap.AddCallerSkip, the skips of several
+// options add up.
+func WithCallerSkip(n int) Option {
+	return func(o *options) {
+		o.callerSkip += n
+	}
+}
+
+// WithCaller enables or disables the caller field, which is enabled by
+// default. Disabling it saves the cost of runtime.Caller on every entry
+// written, which matters for very high-volume logs.
+func WithCaller(enabled bool) Option {
+	return func(o *options) {
+		o.noCaller = !enabled
+	}
+}
+
+// WithTraceFieldsMinLevel makes the context-aware methods attach the trace and
+// span IDs only to entries at or above the given level. High-volume debug and
+// info lines stay small while warnings and errors keep their correlation. The
+// correlation and request IDs are still added to every entry.
+func WithTraceFieldsMinLevel(level Level) Option {
+	return func(o *options) {
+		o.traceMinLevel = level
+	}
+}
+
+// WithTraceSampling makes the trace fields follow the sampling decision of
+// the trace: the trace and span IDs are only added for sampled spans and
+// omitted for unsampled spans and contexts without a span, instead of
+// logging the "unknown" trace ID.
+func WithTraceSampling() Option {
+	return func(o *options) {
+		o.traceSampledOnly = true
+	}
+}
+
+// traceMode returns the trace IDs that contexts among key-value arguments
+// add.
+func (o *options) traceMode() traceMode {
+	if o.traceSampledOnly {
+		return traceSampled
+	}
+	return traceValid
+}
+
+// WithOmitEmpty drops fields holding an empty string, a zero number or
+// duration, an empty byte slice or a nil value before they're encoded.
+// Booleans are always kept. Every field of every entry is inspected, so this
+// adds a small per-field cost.
+func WithOmitEmpty() Option {
+	return func(o *options) {
+		o.omitEmpty = true
+	}
+}
+
+// DanglingKeyPolicy decides what happens to the last argument when a logging
+// call gets an odd number of key-value arguments.
+type DanglingKeyPolicy int
+
+const (
+	// DanglingKeyDPanic drops the argument and logs a DPanic entry about it,
+	// which panics in development. This is the default.
+	DanglingKeyDPanic DanglingKeyPolicy = iota
+	// DanglingKeyAsValue logs the argument as the value of the "extra" key.
+	DanglingKeyAsValue
+	// DanglingKeyDrop silently drops the argument.
+	DanglingKeyDrop
+)
+
+var danglingKeyPolicyNames = map[DanglingKeyPolicy]string{
+	DanglingKeyDPanic:  `dpanic`,
+	DanglingKeyAsValue: `value`,
+	DanglingKeyDrop:    `drop`,
+}
+
+// MarshalText marshals the policy to "dpanic", "value" or "drop".
+func (p DanglingKeyPolicy) MarshalText() ([]byte, error) {
+	name, ok := danglingKeyPolicyNames[p]
+	if !ok {
+		return nil, fmt.Errorf(`log: unknown dangling key policy %d`, int(p))
+	}
+	return []byte(name), nil
+}
+
+// UnmarshalText unmarshals "dpanic", "value" or "drop" to a policy.
+func (p *DanglingKeyPolicy) UnmarshalText(text []byte) error {
+	for policy, name := range danglingKeyPolicyNames {
+		if name == string(text) {
+			*p = policy
+			return nil
+		}
+	}
+	return fmt.Errorf(`log: unknown dangling key policy %q`, text)
+}
+
+// WithDanglingKeyPolicy sets how a key without a value is handled.
+func WithDanglingKeyPolicy(policy DanglingKeyPolicy) Option {
+	return func(o *options) {
+		o.danglingKey = policy
+	}
+}
+
+// newCore builds the core writing the entries enabled by level, together
+// with the cores enabled by the options.
+func (o *options) newCore(level zapcore.LevelEnabler) zapcore.Core {
+	var core zapcore.Core = nopCore{level}
+	if o.sink != nil {
+		core = o.newSinkCore(level)
+	}
+	if len(o.tee) > 0 {
+		cores := []zapcore.Core{core}
+		for _, c := range o.tee {
+			cores = append(cores, &gatedCore{Core: c, level: level})
+		}
+		core = zapcore.NewTee(cores...)
+	}
+	if len(o.hooks) > 0 {
+		core = zapcore.RegisterHooks(core, o.hooks...)
+	}
+	if o.webhookCore != nil {
+		core = zapcore.NewTee(core, &gatedCore{Core: o.webhookCore, level: level})
+	}
+	return core
+}
+
+// newSinkCore builds the core writing the entries enabled by level to the
+// sink.
+func (o *options) newSinkCore(level zapcore.LevelEnabler) zapcore.Core {
+	var core zapcore.Core
+	if w, ok := o.sink.(*syslogWriter); ok {
+		core = newSyslogCore(o.newEncoder(), w, o.syslogTag, level)
+	} else if o.errSink != nil {
+		core = newSplitCore(o.newEncoder(), o.sink, o.errSink, level)
+	} else {
+		core = zapcore.NewCore(o.newEncoder(), o.sink, level)
+	}
+	if o.squelchRepeats {
+		core = newSquelchCore(core)
+	}
+	if o.sampling != nil {
+		if sampled := o.sampling.wrap(core); o.startupBurst > 0 {
+			core = newBurstCore(core, sampled, o.burstUntil)
+		} else {
+			core = sampled
+		}
+	}
+	return core
+}
+
+// nopCore discards every entry but reports the entries enabled by its level
+// as enabled, so the level of a nop logger behaves like the one of a real
+// logger.
+type nopCore struct {
+	zapcore.LevelEnabler
+}
+
+func (c nopCore) With([]Field) zapcore.Core { return c }
+
+func (nopCore) Check(_ zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry { return ce }
+
+func (nopCore) Write(zapcore.Entry, []Field) error { return nil }
+
+func (nopCore) Sync() error { return nil }
+
+func (o *options) newEncoder() zapcore.Encoder {
+	switch o.format {
+	case formatConsole:
+		return zapcore.NewConsoleEncoder(encoderConfig(o.encoderConfig))
+	case formatLogfmt:
+		return newLogfmtEncoder(encoderConfig(o.encoderConfig))
+	case formatGELF:
+		return newGELFEncoder()
+	}
+	return zapcore.NewJSONEncoder(encoderConfig(o.encoderConfig))
+}
+
+func (o *options) zapOptions() []zap.Option {
+	opts := []zap.Option{
+		// zap resolves the caller only after the core has accepted the entry,
+		// so entries rejected by the level (or a sampling core) never pay for
+		// runtime.Caller.
+		zap.WithCaller(!o.noCaller),
+		zap.AddCallerSkip(o.callerSkip),
+		zap.WithClock(o.clock),
+	}
+	if o.stackFilter == nil {
+		opts = append(opts, zap.AddStacktrace(o.stacktraceLevel))
+	} else {
+		// The stacktrace is captured and filtered by logw and logf instead.
+		opts = append(opts, zap.AddStacktrace(zap.LevelEnablerFunc(func(Level) bool { return false })))
+	}
+	if o.development {
+		opts = append(opts, zap.Development())
+	}
+	return opts
+}
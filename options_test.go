@@ -0,0 +1,150 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWithWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewLogger(WithWriter(buf))
+	ctx := context.Background()
+
+	logger.Debug(ctx, `hidden`)
+	logger.SetLevel(DebugLevel)
+	logger.Debug(ctx, `shown`)
+	if e := decodeLine(t, buf); e[`message`] != `shown` {
+		t.Errorf("got %v, want only the entry enabled by the level", e)
+	}
+}
+
+func TestDefaultWriter(t *testing.T) {
+	if sink := NewLogger().opts.sink; sink != stderr {
+		t.Errorf("got sink %#v, want stderr", sink)
+	}
+}
+
+// BenchmarkSampledCaller shows that entries dropped by the sampler don't pay
+// for the caller: with most entries dropped, enabling the caller costs about
+// as little as disabling it, while without sampling every entry resolves it.
+func BenchmarkSampledCaller(b *testing.B) {
+	for _, bm := range []struct {
+		name string
+		opts []Option
+	}{
+		{`sampled/caller`, []Option{WithSampling(1, 1000)}},
+		{`sampled/nocaller`, []Option{WithSampling(1, 1000), WithCaller(false)}},
+		{`unsampled/caller`, nil},
+		{`unsampled/nocaller`, []Option{WithCaller(false)}},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			logger := NewLogger(append([]Option{WithWriter(io.Discard)}, bm.opts...)...)
+			ctx := context.Background()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				logger.Info(ctx, `sampled`)
+			}
+		})
+	}
+}
+
+func TestWithTraceSampling(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		ctx     context.Context
+		sampled bool
+		traceID interface{}
+	}{
+		{`sampled`, spanContext(true), true, testTraceID},
+		{`unsampled`, spanContext(false), true, nil},
+		{`no span`, context.Background(), true, nil},
+		{`unsampled by default`, spanContext(false), false, testTraceID},
+		{`no span by default`, context.Background(), false, NoTraceId},
+	} {
+		var opts []Option
+		if tt.sampled {
+			opts = append(opts, WithTraceSampling())
+		}
+		logger, buf := newBufferLogger(opts...)
+		logger.Info(tt.ctx, `context`)
+		if e := decodeLine(t, buf); e[`traceId`] != tt.traceID {
+			t.Errorf("%s: got traceId %v, want %v", tt.name, e[`traceId`], tt.traceID)
+		}
+
+		// Contexts among the key-value pairs never add the unknown trace ID.
+		buf.Reset()
+		logger.Infow(`key-value`, tt.ctx)
+		want := tt.traceID
+		if want == NoTraceId {
+			want = nil
+		}
+		if e := decodeLine(t, buf); e[`traceId`] != want {
+			t.Errorf("%s: got traceId %v from a key-value context, want %v", tt.name, e[`traceId`], want)
+		}
+	}
+}
+
+// infoHelper wraps Info, like the logging helpers of an application.
+func infoHelper(l *Logger, msg string) {
+	l.Info(context.Background(), msg)
+}
+
+func TestWithCallerSkip(t *testing.T) {
+	logger, buf := newBufferLogger(WithCallerSkip(1))
+	_, _, line, _ := runtime.Caller(0)
+	infoHelper(logger, `helper`)
+	logger.Zap().Info(`zap`)
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	for i, e := range entries {
+		want := `options_test.go:` + strconv.Itoa(line+1+i)
+		if caller, _ := e[`caller`].(string); !strings.HasSuffix(caller, want) {
+			t.Errorf("got caller %s for %v, want %s", caller, e[`message`], want)
+		}
+	}
+}
+
+func TestWithCaller(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opts []Option
+		want bool
+	}{
+		{`default`, nil, true},
+		{`enabled`, []Option{WithCaller(true)}, true},
+		{`disabled`, []Option{WithCaller(false)}, false},
+	} {
+		logger, buf := newBufferLogger(tt.opts...)
+		logger.Info(context.Background(), `info`)
+		logger.Zap().Info(`zap`)
+		for _, e := range decodeLines(t, buf) {
+			if _, ok := e[`caller`]; ok != tt.want {
+				t.Errorf("%s: got %v, want a caller %v", tt.name, e, tt.want)
+			}
+		}
+	}
+}
+
+// BenchmarkWithCaller shows the cost of the caller field on every entry.
+func BenchmarkWithCaller(b *testing.B) {
+	for _, enabled := range []bool{true, false} {
+		b.Run(`caller=`+strconv.FormatBool(enabled), func(b *testing.B) {
+			logger := NewLogger(WithWriter(io.Discard), WithCaller(enabled))
+			ctx := context.Background()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				logger.Info(ctx, `request served`, `status`, 200)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Output types of OutputConfig.
+const (
+	outputStderr = `stderr`
+	outputStdout = `stdout`
+	outputSplit  = `split`
+	outputFile   = `file`
+	outputSyslog = `syslog`
+)
+
+// OutputConfig describes where a logger writes, as part of a LoggerConfig.
+type OutputConfig struct {
+	// Type is "stderr", the default, "stdout", "split" for WithSplitStreams,
+	// "file" for WithRotatingFile or "syslog" for WithSyslog.
+	Type string `json:"type" yaml:"type"`
+	// Path is the file written by the file output.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// MaxSizeMB, MaxBackups and MaxAgeDays are the rotation limits of the
+	// file output, see WithRotatingFile. Zero means no limit, so a file
+	// output without MaxSizeMB is never rotated by size.
+	MaxSizeMB  int `json:"maxSizeMB,omitempty" yaml:"maxSizeMB,omitempty"`
+	MaxBackups int `json:"maxBackups,omitempty" yaml:"maxBackups,omitempty"`
+	MaxAgeDays int `json:"maxAgeDays,omitempty" yaml:"maxAgeDays,omitempty"`
+	// Network, Addr and Tag are the arguments of WithSyslog for the syslog
+	// output.
+	Network string `json:"network,omitempty" yaml:"network,omitempty"`
+	Addr    string `json:"addr,omitempty" yaml:"addr,omitempty"`
+	Tag     string `json:"tag,omitempty" yaml:"tag,omitempty"`
+	// Buffer, if set, enables WithBufferedWriter.
+	Buffer *BufferConfig `json:"buffer,omitempty" yaml:"buffer,omitempty"`
+}
+
+// BufferConfig describes the buffer of WithBufferedWriter.
+type BufferConfig struct {
+	FlushInterval time.Duration `json:"flushInterval,omitempty" yaml:"flushInterval,omitempty"`
+	Size          int           `json:"size,omitempty" yaml:"size,omitempty"`
+}
+
+// outputConfig describes the output of o. It returns nil for the default
+// output, and for writers passed to WithWriter other than os.Stdout and
+// os.Stderr, which can't be described.
+func (o *options) outputConfig() *OutputConfig {
+	var out OutputConfig
+	switch w := o.writer.(type) {
+	case *rotatingFile:
+		out = OutputConfig{
+			Type:       outputFile,
+			Path:       w.path,
+			MaxSizeMB:  int(w.maxSize / megabyte),
+			MaxBackups: w.maxBackups,
+			MaxAgeDays: int(w.maxAge / (24 * time.Hour)),
+		}
+	case *os.File:
+		switch w {
+		case os.Stdout:
+			out.Type = outputStdout
+		case os.Stderr:
+			out.Type = outputStderr
+		default:
+			return nil
+		}
+	case nil:
+		switch s := unwrapSink(o.sink).(type) {
+		case nil:
+			return nil
+		case *syslogWriter:
+			out = OutputConfig{Type: outputSyslog, Network: s.network, Addr: s.addr, Tag: o.syslogTag}
+		default:
+			if o.errSink != nil {
+				out.Type = outputSplit
+			} else {
+				out.Type = outputStderr
+			}
+		}
+	default:
+		return nil
+	}
+	if o.buffer != nil {
+		out.Buffer = &BufferConfig{FlushInterval: o.buffer.flushInterval, Size: o.buffer.size}
+	} else if out.Type == outputStderr {
+		return nil
+	}
+	return &out
+}
+
+// options returns the options applying out.
+func (out OutputConfig) options() ([]Option, error) {
+	var opts []Option
+	switch out.Type {
+	case ``, outputStderr:
+	case outputStdout:
+		opts = append(opts, WithWriter(os.Stdout))
+	case outputSplit:
+		opts = append(opts, WithSplitStreams())
+	case outputFile:
+		if out.Path == `` {
+			return nil, errors.New(`log: output.path must not be empty for the file output`)
+		}
+		if out.MaxSizeMB < 0 || out.MaxBackups < 0 || out.MaxAgeDays < 0 {
+			return nil, fmt.Errorf(`log: output.maxSizeMB, output.maxBackups and output.maxAgeDays must not be negative, got %d, %d and %d`, out.MaxSizeMB, out.MaxBackups, out.MaxAgeDays)
+		}
+		opts = append(opts, WithRotatingFile(out.Path, out.MaxSizeMB, out.MaxBackups, out.MaxAgeDays))
+	case outputSyslog:
+		opts = append(opts, WithSyslog(out.Network, out.Addr, out.Tag))
+	default:
+		return nil, fmt.Errorf(`log: invalid output.type %q, want stderr, stdout, split, file or syslog`, out.Type)
+	}
+	if b := out.Buffer; b != nil {
+		if b.FlushInterval < 0 || b.Size < 0 {
+			return nil, fmt.Errorf(`log: output.buffer.flushInterval and output.buffer.size must not be negative, got %s and %d`, b.FlushInterval, b.Size)
+		}
+		opts = append(opts, WithBufferedWriter(b.FlushInterval, b.Size))
+	}
+	return opts, nil
+}
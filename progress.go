@@ -0,0 +1,78 @@
+package log
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultProgressInterval = 10 * time.Second
+
+// WithProgressInterval sets how often Progress logs each operation. It
+// defaults to 10 seconds.
+func WithProgressInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.progressInterval = d
+	}
+}
+
+type progressThrottle struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+func newProgressThrottle() *progressThrottle {
+	return &progressThrottle{last: map[string]time.Time{}}
+}
+
+// allow reports whether op may be logged at now, and records it if so.
+// Completed ops are forgotten, and so are the ops that haven't been logged
+// for an interval when a new one starts, so abandoned ops don't pile up.
+func (p *progressThrottle) allow(op string, now time.Time, interval time.Duration, done bool) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if done {
+		delete(p.last, op)
+		return true
+	}
+	last, ok := p.last[op]
+	if ok && now.Sub(last) < interval {
+		return false
+	}
+	if !ok {
+		for o, t := range p.last {
+			if now.Sub(t) >= interval {
+				delete(p.last, o)
+			}
+		}
+	}
+	p.last[op] = now
+	return true
+}
+
+// Progress logs the progress of a long-running operation at info level with
+// the op, current, total and percent fields. Each op is logged at most once
+// per progress interval (see WithProgressInterval), except for the final
+// call where current reaches total, which is always logged.
+func (l *Logger) Progress(ctx context.Context, op string, current, total int64) {
+	if !l.base.Core().Enabled(zapcore.InfoLevel) {
+		return
+	}
+	if !l.progress.allow(op, l.opts.clock.Now(), l.opts.progressInterval, current >= total) {
+		return
+	}
+	var percent float64
+	if total > 0 {
+		percent = math.Round(float64(current)/float64(total)*10000) / 100
+	}
+	l.logw(nonNil(ctx), zapcore.InfoLevel, `progress`, []interface{}{
+		zap.String(`op`, op),
+		zap.Int64(`current`, current),
+		zap.Int64(`total`, total),
+		zap.Float64(`percent`, percent),
+	})
+}
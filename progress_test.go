@@ -0,0 +1,57 @@
+package log
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	clock := newTestClock()
+	logger, buf := newBufferLogger(WithClock(clock), WithProgressInterval(time.Minute))
+	ctx := context.Background()
+
+	logger.Progress(ctx, `import`, 1, 3)
+	logger.Progress(ctx, `import`, 2, 3)  // throttled
+	logger.Progress(ctx, `migrate`, 0, 0) // done, and another op
+	clock.Add(time.Minute)
+	logger.Progress(ctx, `import`, 2, 3)
+	logger.Progress(ctx, `import`, 3, 3) // done, logged despite the throttle
+
+	entries := decodeLines(t, buf)
+	want := []struct {
+		op      string
+		current float64
+		percent float64
+	}{
+		{`import`, 1, 33.33},
+		{`migrate`, 0, 0},
+		{`import`, 2, 66.67},
+		{`import`, 3, 100},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e[`op`] != w.op || e[`current`] != w.current || e[`percent`] != w.percent {
+			t.Errorf("got entry %v, want op %s, current %v and percent %v", e, w.op, w.current, w.percent)
+		}
+	}
+}
+
+func TestProgressForgetsOps(t *testing.T) {
+	p := newProgressThrottle()
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	p.allow(`import`, now, time.Minute, false)
+	p.allow(`abandoned`, now, time.Minute, false)
+	p.allow(`import`, now, time.Minute, true)
+	if _, ok := p.last[`import`]; ok {
+		t.Error("got the completed op tracked, want it forgotten")
+	}
+	p.allow(`export`, now.Add(time.Minute), time.Minute, false)
+	if len(p.last) != 1 {
+		t.Errorf("got ops %v, want only export tracked", p.last)
+	}
+}
@@ -0,0 +1,27 @@
+package log
+
+import (
+	"context"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	publishedMsg     = `message published`
+	publishFailedMsg = `message publish failed`
+)
+
+// PublishResult logs the outcome of publishing a message to a kafka topic with
+// the Topic, Partition and Offset fields: at info level on success, or at
+// error level with the Error field when err isn't nil.
+func (l *Logger) PublishResult(ctx context.Context, topic string, partition int, offset int64, err error) {
+	if err != nil {
+		l.logw(nonNil(ctx), zapcore.ErrorLevel, publishFailedMsg, []interface{}{
+			Topic(topic), Partition(partition), Offset(offset), Error(err),
+		})
+		return
+	}
+	l.logw(nonNil(ctx), zapcore.InfoLevel, publishedMsg, []interface{}{
+		Topic(topic), Partition(partition), Offset(offset),
+	})
+}
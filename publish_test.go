@@ -0,0 +1,33 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPublishResult(t *testing.T) {
+	logger, buf := newBufferLogger()
+	ctx := context.Background()
+	logger.PublishResult(ctx, `orders`, 3, 42, nil)
+	logger.PublishResult(ctx, `orders`, 3, -1, errors.New(`broker unavailable`))
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	for i, want := range []struct {
+		level, message string
+		offset         float64
+		err            interface{}
+	}{
+		{`info`, publishedMsg, 42, nil},
+		{`error`, publishFailedMsg, -1, `broker unavailable`},
+	} {
+		e := entries[i]
+		if e[`level`] != want.level || e[`message`] != want.message || e[`topic`] != `orders` ||
+			e[`partition`] != 3.0 || e[`offset`] != want.offset || e[`error`] != want.err {
+			t.Errorf("got %v, want a %s entry with offset %v and error %v", e, want.level, want.offset, want.err)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package log
+
+import (
+	"context"
+
+	"go.uber.org/zap/zapcore"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying an application-level request
+// ID, such as the X-Request-ID header. The context-aware logging methods add
+// it to every entry as the request_id field.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(nonNil(ctx), requestIDKey{}, id)
+}
+
+// RequestID constructs a field that carries a request ID.
+func RequestID(value string) Field {
+	return Field{Key: `request_id`, Type: zapcore.StringType, String: value}
+}
+
+// RequestIDFromContext constructs a field that carries the request ID stored
+// in ctx by WithRequestID. It's skipped if ctx has none.
+func RequestIDFromContext(ctx context.Context) Field {
+	if id, ok := requestIDFromContext(ctx); ok {
+		return RequestID(id)
+	}
+	return Skip()
+}
+
+func requestIDFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return ``, false
+	}
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok
+}
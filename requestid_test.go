@@ -0,0 +1,42 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRequestID(t *testing.T) {
+	if f := RequestID(`req-1`); f.Key != `request_id` || f.Type != zapcore.StringType || f.String != `req-1` {
+		t.Errorf("got %+v, want a request_id string field", f)
+	}
+
+	ctx := WithRequestID(context.Background(), `req-1`)
+	if f := RequestIDFromContext(ctx); f.Key != `request_id` || f.String != `req-1` {
+		t.Errorf("got %+v, want the request ID of the context", f)
+	}
+	for name, ctx := range map[string]context.Context{`empty`: context.Background(), `nil`: nil} {
+		if f := RequestIDFromContext(ctx); f.Type != zapcore.SkipType {
+			t.Errorf("got %+v for a %s context, want a skipped field", f, name)
+		}
+	}
+	// A nil context is accepted, like in the logging methods.
+	if f := RequestIDFromContext(WithRequestID(nil, `req-2`)); f.String != `req-2` {
+		t.Errorf("got %+v, want the request ID set on a nil context", f)
+	}
+
+	logger, buf := newBufferLogger(WithTraceFieldsMinLevel(ErrorLevel))
+	logger.Info(ctx, `with`)
+	logger.Info(context.Background(), `without`)
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0][`request_id`] != `req-1` {
+		t.Errorf("got %v, want the request ID even below the trace fields level", entries[0])
+	}
+	if id, ok := entries[1][`request_id`]; ok {
+		t.Errorf("got request_id %v without one in the context, want none", id)
+	}
+}
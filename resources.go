@@ -0,0 +1,43 @@
+package log
+
+import (
+	"runtime"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ResourceUsage constructs a field that carries the CPU time and memory
+// attributed to a unit of work, such as a request.
+func ResourceUsage(cpuMillis int64, memBytes int64) Field {
+	return Object(`resources`, resourceUsage{cpuMillis: cpuMillis, memBytes: memBytes})
+}
+
+type resourceUsage struct {
+	cpuMillis, memBytes int64
+}
+
+func (r resourceUsage) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddInt64(`cpu_ms`, r.cpuMillis)
+	enc.AddInt64(`mem_bytes`, r.memBytes)
+	return nil
+}
+
+// TrackResources snapshots the process CPU time and the bytes allocated so
+// far, and returns a function that builds a ResourceUsage field from the
+// deltas since the snapshot. The figures are process-wide, so concurrent work
+// is included.
+//
+// Each snapshot calls runtime.ReadMemStats, which stops the world; only use
+// it where the cost is acceptable.
+func TrackResources() func() Field {
+	cpu, mem := cpuTime(), allocatedBytes()
+	return func() Field {
+		return ResourceUsage((cpuTime() - cpu).Milliseconds(), int64(allocatedBytes()-mem))
+	}
+}
+
+func allocatedBytes() uint64 {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return ms.TotalAlloc
+}
@@ -0,0 +1,28 @@
+package log
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResourceUsage(t *testing.T) {
+	got := encodeFields(t, ResourceUsage(12, 2048))
+	want := map[string]interface{}{`resources`: map[string]interface{}{`cpu_ms`: 12.0, `mem_bytes`: 2048.0}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+var allocated []byte
+
+func TestTrackResources(t *testing.T) {
+	done := TrackResources()
+	allocated = make([]byte, 1<<20)
+	usage := encodeFields(t, done())[`resources`].(map[string]interface{})
+	if mem := usage[`mem_bytes`].(float64); mem < 1<<20 {
+		t.Errorf("got mem_bytes %v, want at least the 1MiB allocated", mem)
+	}
+	if cpu := usage[`cpu_ms`].(float64); cpu < 0 {
+		t.Errorf("got cpu_ms %v, want a non-negative value", cpu)
+	}
+}
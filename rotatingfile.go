@@ -0,0 +1,217 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	megabyte         = 1024 * 1024
+	backupTimeFormat = `2006-01-02T15-04-05.000`
+)
+
+// WithRotatingFile makes the logger write to the file at path, starting a new
+// file once the current one would grow beyond maxSizeMB megabytes. The full
+// file is renamed to a backup with a timestamp in its name, next to path. At
+// most maxBackups backups are kept and backups older than maxAgeDays days are
+// removed; zero means no limit. The file is created when the first entry is
+// written, and Sync flushes it to disk. Logger.Rotate starts a new file on
+// demand.
+func WithRotatingFile(path string, maxSizeMB, maxBackups, maxAgeDays int) Option {
+	return func(o *options) {
+		f := &rotatingFile{
+			path:       path,
+			maxSize:    int64(maxSizeMB) * megabyte,
+			maxBackups: maxBackups,
+			maxAge:     time.Duration(maxAgeDays) * 24 * time.Hour,
+			clock:      zapcore.DefaultClock,
+		}
+		o.sink, o.writer = f, f
+		o.errSink = nil
+	}
+}
+
+// rotatingFile is a sink writing to a file that is rotated by size.
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	maxAge     time.Duration
+	// clock dates the backups, it's set to the logger's by newOptions.
+	clock zapcore.Clock
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *rotatingFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Sync()
+}
+
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// Rotate starts a new file, even if the current one isn't full.
+func (f *rotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rotate()
+}
+
+// open opens path for appending, creating it and its directory if needed.
+func (f *rotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf(`log: can't create log directory: %w`, err)
+	}
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf(`log: can't open log file: %w`, err)
+	}
+	fi, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf(`log: can't stat log file: %w`, err)
+	}
+	f.file, f.size = file, fi.Size()
+	return nil
+}
+
+// rotate moves the current file to a backup, opens a new one and removes the
+// backups that are too many or too old.
+func (f *rotatingFile) rotate() error {
+	if f.file != nil {
+		if err := f.file.Close(); err != nil {
+			return fmt.Errorf(`log: can't close log file: %w`, err)
+		}
+		f.file = nil
+	}
+	if err := os.Rename(f.path, f.backupName(f.clock.Now())); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf(`log: can't rotate log file: %w`, err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	f.prune()
+	return nil
+}
+
+// backupName returns an unused name for a backup made at t, e.g.
+// app-<time>.log for app.log, or app-<time>.1.log, app-<time>.2.log, ... if
+// backups were already made within the same millisecond. The time is in the
+// local time zone, which parseBackup assumes.
+func (f *rotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(f.path)
+	base := strings.TrimSuffix(f.path, ext) + `-` + t.Local().Format(backupTimeFormat)
+	name := base + ext
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(name); os.IsNotExist(err) {
+			return name
+		}
+		name = base + `.` + strconv.Itoa(i) + ext
+	}
+}
+
+// parseBackup returns the time and the sequence number of a backup from its
+// name stripped of the prefix and extension of the file.
+func parseBackup(s string) (time.Time, int, bool) {
+	if len(s) < len(backupTimeFormat) {
+		return time.Time{}, 0, false
+	}
+	t, err := time.ParseInLocation(backupTimeFormat, s[:len(backupTimeFormat)], time.Local)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	seq := 0
+	if rest := s[len(backupTimeFormat):]; rest != `` {
+		if rest[0] != '.' {
+			return time.Time{}, 0, false
+		}
+		if seq, err = strconv.Atoi(rest[1:]); err != nil || seq < 1 {
+			return time.Time{}, 0, false
+		}
+	}
+	return t, seq, true
+}
+
+// prune removes old backups. Errors are ignored, a backup that can't be
+// removed is retried on the next rotation.
+func (f *rotatingFile) prune() {
+	if f.maxBackups <= 0 && f.maxAge <= 0 {
+		return
+	}
+	ext := filepath.Ext(f.path)
+	prefix := strings.TrimSuffix(filepath.Base(f.path), ext) + `-`
+	entries, err := os.ReadDir(filepath.Dir(f.path))
+	if err != nil {
+		return
+	}
+	type backup struct {
+		name string
+		t    time.Time
+		seq  int
+	}
+	var backups []backup
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		t, seq, ok := parseBackup(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext))
+		if !ok {
+			continue
+		}
+		backups = append(backups, backup{name: filepath.Join(filepath.Dir(f.path), name), t: t, seq: seq})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].t.Equal(backups[j].t) {
+			return backups[i].t.After(backups[j].t)
+		}
+		return backups[i].seq > backups[j].seq
+	})
+	now := f.clock.Now()
+	for i, b := range backups {
+		if (f.maxBackups > 0 && i >= f.maxBackups) || (f.maxAge > 0 && now.Sub(b.t) > f.maxAge) {
+			_ = os.Remove(b.name)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// backups returns the contents of the backups of path, sorted.
+func backups(t *testing.T, path string) []string {
+	t.Helper()
+	names, err := filepath.Glob(strings.TrimSuffix(path, `.log`) + `-*.log`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := make([]string, 0, len(names))
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents = append(contents, string(data))
+	}
+	sort.Strings(contents)
+	return contents
+}
+
+func TestRotatingFileSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `logs`, `app.log`)
+	f := &rotatingFile{path: path, maxSize: 10, clock: newTestClock()}
+	defer f.Close()
+
+	// Every line is written within the same millisecond, so the backups
+	// only differ by their sequence number.
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "third\n" {
+		t.Errorf("got %q in the active file, want only the last line", data)
+	}
+	if got := backups(t, path); len(got) != 2 || got[0] != "first\n" || got[1] != "second\n" {
+		t.Errorf("got backups %q, want the first and second lines", got)
+	}
+}
+
+func TestRotatingFileOversizedWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `app.log`)
+	f := &rotatingFile{path: path, maxSize: 4, clock: newTestClock()}
+	defer f.Close()
+
+	// A write larger than the maximum goes to an empty file rather than
+	// rotating forever.
+	if _, err := f.Write([]byte("oversized\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got := backups(t, path); len(got) != 0 {
+		t.Errorf("got backups %q, want none", got)
+	}
+}
+
+func TestRotatingFilePrune(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `app.log`)
+	clock := newTestClock()
+	f := &rotatingFile{path: path, maxBackups: 2, maxAge: 3 * 24 * time.Hour, clock: clock}
+	defer f.Close()
+
+	for _, line := range []string{"1\n", "2\n", "3\n", "4\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+		clock.Add(24 * time.Hour)
+		if err := f.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := backups(t, path); len(got) != 2 || got[0] != "3\n" || got[1] != "4\n" {
+		t.Errorf("got backups %q, want the 2 newest", got)
+	}
+
+	// The age is measured with the clock of the logger, not the system's.
+	clock.Add(3*24*time.Hour + time.Minute)
+	if _, err := f.Write([]byte("5\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if got := backups(t, path); len(got) != 1 || got[0] != "5\n" {
+		t.Errorf("got backups %q, want only the one younger than the maximum age", got)
+	}
+}
+
+func TestRotatingFileClock(t *testing.T) {
+	clock := newTestClock()
+	logger := NewLogger(WithRotatingFile(filepath.Join(t.TempDir(), `app.log`), 1, 0, 0), WithClock(clock))
+	defer logger.Close()
+	if f, ok := logger.opts.writer.(*rotatingFile); !ok || f.clock != clock {
+		t.Errorf("got writer %#v, want a rotating file using the logger's clock", logger.opts.writer)
+	}
+}
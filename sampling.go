@@ -0,0 +1,27 @@
+package log
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// SamplingConfig describes the sampler of WithSampling.
+type SamplingConfig struct {
+	First      int `json:"first" yaml:"first"`
+	Thereafter int `json:"thereafter" yaml:"thereafter"`
+}
+
+// WithSampling protects against log floods: of the entries with the same
+// level and message logged within a second, the first are written and then
+// only every thereafter-th one. Sampling is off by default and never enables
+// entries below the logger's level.
+func WithSampling(first, thereafter int) Option {
+	return func(o *options) {
+		o.sampling = &SamplingConfig{First: first, Thereafter: thereafter}
+	}
+}
+
+func (cfg SamplingConfig) wrap(core zapcore.Core) zapcore.Core {
+	return zapcore.NewSamplerWithOptions(core, time.Second, cfg.First, cfg.Thereafter)
+}
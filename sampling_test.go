@@ -0,0 +1,35 @@
+package log
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSampling(t *testing.T) {
+	logger, buf := newBufferLogger(WithSampling(2, 10), WithClock(newTestClock()))
+	ctx := context.Background()
+	for i := 0; i < 100; i++ {
+		logger.Error(ctx, `flood`)
+	}
+	logger.Info(ctx, `other`)
+
+	counts := map[interface{}]int{}
+	for _, e := range decodeLines(t, buf) {
+		counts[e[`message`]]++
+	}
+	// The first 2 pass, then every 10th of the remaining 98.
+	if counts[`flood`] != 11 || counts[`other`] != 1 {
+		t.Errorf("got %v, want 11 flood entries and the other one", counts)
+	}
+}
+
+func TestSamplingRespectsLevel(t *testing.T) {
+	logger, buf := newBufferLogger(WithSampling(1, 1))
+	ctx := context.Background()
+	logger.Debug(ctx, `hidden`)
+	logger.SetLevel(DebugLevel)
+	logger.Debug(ctx, `shown`)
+	if e := decodeLine(t, buf); e[`message`] != `shown` {
+		t.Errorf("got %v, want only the entry enabled by the level", e)
+	}
+}
@@ -0,0 +1,37 @@
+package log
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// FlushOnSignal syncs the logger whenever one of sigs arrives, so buffered
+// entries reach their output during a graceful shutdown. Call stop to
+// unregister. Sync errors are ignored, there's nowhere left to report them.
+//
+// Like signal.Notify, registering a signal such as SIGTERM stops it from
+// terminating the program, so the program has to handle it itself, e.g. with
+// signal.NotifyContext.
+func (l *Logger) FlushOnSignal(sigs ...os.Signal) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+	go func() {
+		for {
+			select {
+			case <-ch:
+				_ = l.Sync()
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package log
+
+import (
+	"bytes"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// syncNotifier is a writer that reports its Sync calls on a channel.
+type syncNotifier struct {
+	bytes.Buffer
+	synced chan struct{}
+}
+
+func (w *syncNotifier) Sync() error {
+	w.synced <- struct{}{}
+	return nil
+}
+
+func TestFlushOnSignal(t *testing.T) {
+	w := &syncNotifier{synced: make(chan struct{}, 1)}
+	stop := NewLogger(WithWriter(w)).FlushOnSignal(syscall.SIGUSR1)
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-w.synced:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for Sync")
+	}
+
+	// stop can be called more than once.
+	stop()
+	stop()
+}
@@ -0,0 +1,110 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync/atomic"
+	"syscall"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+// brokenPipeSink drops writes once the underlying writer reports a broken
+// pipe, e.g. when logs are piped into a consumer like `head` that exits early.
+// The first EPIPE is returned so the core reports it once to its error
+// output; every write after that is discarded silently.
+//
+// Note that for file descriptors 1 and 2 the Go runtime raises SIGPIPE on a
+// broken pipe unless the program has called signal.Notify for it, so the sink
+// only sees EPIPE when the signal is handled or ignored.
+type brokenPipeSink struct {
+	zapcore.WriteSyncer
+	broken int32
+}
+
+func newBrokenPipeSink(ws zapcore.WriteSyncer) *brokenPipeSink {
+	return &brokenPipeSink{WriteSyncer: ws}
+}
+
+func (s *brokenPipeSink) Write(p []byte) (int, error) {
+	if atomic.LoadInt32(&s.broken) == 1 {
+		return len(p), nil
+	}
+	n, err := s.WriteSyncer.Write(p)
+	if err != nil && errors.Is(err, syscall.EPIPE) {
+		if atomic.CompareAndSwapInt32(&s.broken, 0, 1) {
+			return n, err
+		}
+		return len(p), nil
+	}
+	return n, err
+}
+
+func (s *brokenPipeSink) Sync() error {
+	if atomic.LoadInt32(&s.broken) == 1 {
+		return nil
+	}
+	return s.WriteSyncer.Sync()
+}
+
+// consoleSink ignores the errors of syncing a console or pipe, such as
+// "sync /dev/stderr: invalid argument", which only mean that the device has
+// nothing to flush.
+type consoleSink struct {
+	zapcore.WriteSyncer
+}
+
+func (s consoleSink) Sync() error {
+	err := s.WriteSyncer.Sync()
+	if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTTY) || errors.Is(err, syscall.EBADF) {
+		return nil
+	}
+	return err
+}
+
+// isConsole reports whether w is a file that isn't a regular file, such as a
+// terminal or a pipe.
+func isConsole(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && !fi.Mode().IsRegular()
+}
+
+// rotator is implemented by file sinks that can start a new file on demand,
+// such as the one of WithRotatingFile or a lumberjack.Logger.
+type rotator interface {
+	Rotate() error
+}
+
+// Rotate flushes the logger and makes a rotating file sink start a new file
+// immediately, e.g. before archiving the current one. This works for
+// WithRotatingFile and for writers passed to WithWriter that have a
+// Rotate() error method, such as a lumberjack.Logger. It does nothing for
+// sinks that don't rotate.
+func (l *Logger) Rotate() error {
+	r, ok := l.opts.writer.(rotator)
+	if !ok {
+		return nil
+	}
+	return multierr.Append(l.base.Sync(), r.Rotate())
+}
+
+// unwrapSink returns the sink that the wrappers added by the options write
+// to.
+func unwrapSink(ws zapcore.WriteSyncer) zapcore.WriteSyncer {
+	for {
+		switch s := ws.(type) {
+		case nopCloserSink:
+			ws = s.WriteSyncer
+		case *zapcore.BufferedWriteSyncer:
+			ws = s.WS
+		default:
+			return ws
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// brokenPipe fails every write with EPIPE, like a pipe whose reader exited.
+type brokenPipe struct {
+	writes int
+}
+
+func (w *brokenPipe) Write(p []byte) (int, error) {
+	w.writes++
+	return 0, syscall.EPIPE
+}
+
+func TestBrokenPipe(t *testing.T) {
+	w := &brokenPipe{}
+	errOut := &bytes.Buffer{}
+	logger := NewLogger(WithWriter(w)).WithOptions(zap.ErrorOutput(zapcore.AddSync(errOut)))
+
+	for i := 0; i < 3; i++ {
+		logger.Info(context.Background(), `message`)
+	}
+	if w.writes != 1 {
+		t.Errorf("got %d writes, want 1", w.writes)
+	}
+	if n := strings.Count(errOut.String(), `broken pipe`); n != 1 {
+		t.Errorf("got %d errors reported, want 1: %q", n, errOut)
+	}
+	if err := logger.Sync(); err != nil {
+		t.Errorf("got %v from Sync, want nil", err)
+	}
+}
+
+// readLog decodes the entries of a log file.
+func readLog(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return decodeLines(t, bytes.NewBuffer(data))
+}
+
+func TestRotate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, `app.log`)
+	clock := newTestClock()
+	logger := NewLogger(WithRotatingFile(path, 0, 0, 0), WithClock(clock))
+	defer logger.Close()
+
+	logger.Infow(`before`)
+	if err := logger.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	logger.Infow(`after`)
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want 2", len(files))
+	}
+	backup := filepath.Join(dir, `app-`+clock.Now().Local().Format(backupTimeFormat)+`.log`)
+	for file, want := range map[string]string{backup: `before`, path: `after`} {
+		entries := readLog(t, file)
+		if len(entries) != 1 || entries[0][`message`] != want {
+			t.Errorf("got %v in %s, want only %s", entries, filepath.Base(file), want)
+		}
+	}
+}
+
+// rotatingWriter is a writer with its own rotation, like a lumberjack.Logger.
+type rotatingWriter struct {
+	bytes.Buffer
+	rotations int
+}
+
+func (w *rotatingWriter) Rotate() error {
+	w.rotations++
+	return nil
+}
+
+func TestRotateWriter(t *testing.T) {
+	w := &rotatingWriter{}
+	if err := NewLogger(WithWriter(w)).Rotate(); err != nil || w.rotations != 1 {
+		t.Errorf("got %v and %d rotations, want nil and 1", err, w.rotations)
+	}
+	if err := NewLogger(WithWriter(&bytes.Buffer{})).Rotate(); err != nil {
+		t.Errorf("got %v from a sink that doesn't rotate, want nil", err)
+	}
+}
+
+func TestConsoleSync(t *testing.T) {
+	if err := NewLogger().Sync(); err != nil {
+		t.Errorf("got %v from syncing stderr, want nil", err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	if err := NewLogger(WithWriter(w)).Sync(); err != nil {
+		t.Errorf("got %v from syncing a pipe, want nil", err)
+	}
+
+	// Files still report real errors.
+	f, err := os.Create(filepath.Join(t.TempDir(), `app.log`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := NewLogger(WithWriter(f))
+	f.Close()
+	if err := logger.Sync(); err == nil {
+		t.Error("got nil from syncing a closed file, want an error")
+	}
+}
@@ -0,0 +1,174 @@
+//go:build go1.21
+// +build go1.21
+
+package log
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SlogHandler returns a slog.Handler that writes records through l, so code
+// using log/slog shares this logger's configuration:
+//
+//	logger := slog.New(l.SlogHandler())
+//
+// slog levels are mapped to the nearest zap level at or below them, attributes
+// become fields, and groups become nested objects. The trace fields of the
+// context passed to slog are added like the context-aware methods do, at the
+// top level rather than in the groups.
+func (l *Logger) SlogHandler() slog.Handler {
+	return &slogHandler{l: l}
+}
+
+type slogHandler struct {
+	l *Logger
+	// groups are the groups opened by WithGroup, innermost last. Until a
+	// group is opened, attributes are attached to l.
+	groups []slogGroup
+}
+
+// slogGroup is a group opened by WithGroup with the fields of the attributes
+// added to it by WithAttrs.
+type slogGroup struct {
+	name   string
+	fields []Field
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.l.Enabled(h.l.safeLevel(slogLevel(level)))
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	l := h.l
+	lvl := l.safeLevel(slogLevel(r.Level))
+	ce := l.base.Check(lvl, r.Message)
+	if ce == nil {
+		return nil
+	}
+	if !r.Time.IsZero() {
+		ce.Time = r.Time
+	}
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		ce.Caller = zapcore.EntryCaller{
+			Defined:  true,
+			PC:       frame.PC,
+			File:     frame.File,
+			Line:     frame.Line,
+			Function: frame.Function,
+		}
+	}
+	fields := make([]Field, 0, r.NumAttrs()+1)
+	r.Attrs(func(a slog.Attr) bool {
+		if f, ok := slogField(a); ok {
+			fields = append(fields, f)
+		}
+		return true
+	})
+	if len(h.groups) > 0 {
+		// The groups are nested from the innermost out, after their fields
+		// are transformed. Groups without fields are left out, as slog
+		// handlers are expected to do.
+		fields = l.opts.transform(fields)
+		for i := len(h.groups) - 1; i >= 0; i-- {
+			g := h.groups[i]
+			if content := append(g.fields[:len(g.fields):len(g.fields)], fields...); len(content) > 0 {
+				fields = []Field{Object(g.name, fieldList(content))}
+			}
+		}
+	}
+	fields = appendContextFields(ctx, append(fields, l.traceFields(ctx, lvl)...))
+	ce.Write(l.limit(l.opts.transform(fields))...)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]Field, 0, len(attrs))
+	for _, a := range attrs {
+		if f, ok := slogField(a); ok {
+			fields = append(fields, f)
+		}
+	}
+	if len(h.groups) == 0 {
+		return &slogHandler{l: h.l.With(fields...)}
+	}
+	groups := append([]slogGroup(nil), h.groups...)
+	g := &groups[len(groups)-1]
+	g.fields = append(g.fields[:len(g.fields):len(g.fields)], h.l.opts.transform(fields)...)
+	return &slogHandler{l: h.l, groups: groups}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == `` {
+		return h
+	}
+	groups := append(h.groups[:len(h.groups):len(h.groups)], slogGroup{name: name})
+	return &slogHandler{l: h.l, groups: groups}
+}
+
+// slogLevel maps a slog level to the nearest zap level at or below it.
+func slogLevel(level slog.Level) Level {
+	switch {
+	case level >= slog.LevelError:
+		return ErrorLevel
+	case level >= slog.LevelWarn:
+		return WarnLevel
+	case level >= slog.LevelInfo:
+		return InfoLevel
+	default:
+		return DebugLevel
+	}
+}
+
+// slogField converts an attribute to a field. Empty attributes are dropped,
+// as slog handlers are expected to do.
+func slogField(a slog.Attr) (Field, bool) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return Field{}, false
+	}
+	switch v := a.Value; v.Kind() {
+	case slog.KindString:
+		return String(a.Key, v.String()), true
+	case slog.KindInt64:
+		return Int64(a.Key, v.Int64()), true
+	case slog.KindUint64:
+		return Uint64(a.Key, v.Uint64()), true
+	case slog.KindFloat64:
+		return Float64(a.Key, v.Float64()), true
+	case slog.KindBool:
+		return Bool(a.Key, v.Bool()), true
+	case slog.KindDuration:
+		return Duration(a.Key, v.Duration()), true
+	case slog.KindTime:
+		return zap.Time(a.Key, v.Time()), true
+	case slog.KindGroup:
+		attrs := v.Group()
+		if len(attrs) == 0 {
+			return Field{}, false
+		}
+		fields := make(fieldList, 0, len(attrs))
+		for _, ga := range attrs {
+			if f, ok := slogField(ga); ok {
+				fields = append(fields, f)
+			}
+		}
+		if a.Key == `` {
+			return Field{Type: zapcore.InlineMarshalerType, Interface: fields}, true
+		}
+		return Object(a.Key, fields), true
+	default:
+		if err, ok := v.Any().(error); ok {
+			return NamedError(a.Key, err), true
+		}
+		return Any(a.Key, v.Any()), true
+	}
+}
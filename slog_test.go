@@ -0,0 +1,69 @@
+//go:build go1.21
+// +build go1.21
+
+package log
+
+import (
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	logger, buf := newBufferLogger()
+	sl := slog.New(logger.SlogHandler()).With(`service`, `api`)
+	ctx := spanContext(true)
+
+	sl.DebugContext(ctx, `hidden`)
+	sl.InfoContext(ctx, `served`, `status`, 200, slog.Group(`user`, `id`, 7), slog.Attr{})
+	sl.WithGroup(`req`).Warn(`slow`, `ok`, false)
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	e := entries[0]
+	if e[`level`] != `info` || e[`message`] != `served` || e[`service`] != `api` || e[`traceId`] != testTraceID {
+		t.Errorf("got %v, want an info entry with the attributes and the trace ID", e)
+	}
+	if e[`status`] != 200.0 || !reflect.DeepEqual(e[`user`], map[string]interface{}{`id`: 7.0}) {
+		t.Errorf("got %v, want the status and the user group", e)
+	}
+	if caller, _ := e[`caller`].(string); !strings.Contains(caller, `slog_test.go:`) {
+		t.Errorf("got caller %q, want the slog call site", caller)
+	}
+	if e := entries[1]; e[`level`] != `warn` || !reflect.DeepEqual(e[`req`], map[string]interface{}{`ok`: false}) {
+		t.Errorf("got %v, want a warn entry with the attribute in the req namespace", e)
+	}
+}
+
+func TestSlogHandlerGroups(t *testing.T) {
+	logger, buf := newBufferLogger(WithRedactedKeys(`token`))
+	sl := slog.New(logger.SlogHandler())
+	ctx := spanContext(true)
+
+	sl.With(`service`, `api`).WithGroup(`req`).With(`method`, `GET`, `token`, `secret`).
+		WithGroup(`resp`).InfoContext(ctx, `served`, `status`, 200)
+	sl.WithGroup(`empty`).InfoContext(ctx, `no attributes`)
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	e := entries[0]
+	want := map[string]interface{}{
+		`method`: `GET`,
+		`token`:  redactedValue,
+		`resp`:   map[string]interface{}{`status`: 200.0},
+	}
+	if !reflect.DeepEqual(e[`req`], want) || e[`service`] != `api` {
+		t.Errorf("got %v, want req %v", e, want)
+	}
+	if e[`traceId`] != testTraceID || e[`spanId`] != testSpanID {
+		t.Errorf("got %v, want the trace fields at the top level", e)
+	}
+	if _, ok := entries[1][`empty`]; ok {
+		t.Errorf("got %v, want the empty group left out", entries[1])
+	}
+}
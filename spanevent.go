@@ -0,0 +1,68 @@
+package log
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+)
+
+// WithSpanEvents records entries at or above minLevel as events on the span
+// in the context passed to the context-aware methods, so they show up in
+// tracing backends. The event is named after the message and carries the
+// level and the fields with simple values as attributes. Nothing is recorded
+// when the context has no recording span.
+func WithSpanEvents(minLevel Level) Option {
+	return func(o *options) {
+		o.spanEvents = true
+		o.spanEventLevel = minLevel
+	}
+}
+
+func (l *Logger) addSpanEvent(ctx context.Context, lvl zapcore.Level, msg string, fields []Field) {
+	if !l.opts.spanEvents || lvl < l.opts.spanEventLevel {
+		return
+	}
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	attrs := make([]attribute.KeyValue, 0, len(fields)+1)
+	attrs = append(attrs, attribute.String(`level`, lvl.String()))
+	for _, f := range fields {
+		if a, ok := spanAttribute(f); ok {
+			attrs = append(attrs, a)
+		}
+	}
+	span.AddEvent(msg, trace.WithAttributes(attrs...))
+}
+
+// spanAttribute converts fields with simple values to attributes. The trace
+// fields are skipped since the span already identifies the trace.
+func spanAttribute(f Field) (attribute.KeyValue, bool) {
+	switch f.Key {
+	case `traceId`, `spanId`:
+		return attribute.KeyValue{}, false
+	}
+	switch f.Type {
+	case zapcore.StringType:
+		return attribute.String(f.Key, f.String), true
+	case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type,
+		zapcore.Uint64Type, zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type:
+		return attribute.Int64(f.Key, f.Integer), true
+	case zapcore.BoolType:
+		return attribute.Bool(f.Key, f.Integer == 1), true
+	case zapcore.Float64Type:
+		return attribute.Float64(f.Key, math.Float64frombits(uint64(f.Integer))), true
+	case zapcore.DurationType:
+		return attribute.String(f.Key, time.Duration(f.Integer).String()), true
+	case zapcore.ErrorType:
+		if err, ok := f.Interface.(error); ok {
+			return attribute.String(f.Key, err.Error()), true
+		}
+	}
+	return attribute.KeyValue{}, false
+}
@@ -0,0 +1,80 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type spanEvent struct {
+	name  string
+	attrs []attribute.KeyValue
+}
+
+// recordingSpan records the events added to it, like a span from an SDK
+// tracer that samples everything.
+type recordingSpan struct {
+	noop.Span
+	events []spanEvent
+}
+
+func (s *recordingSpan) IsRecording() bool { return true }
+
+func (s *recordingSpan) SpanContext() trace.SpanContext {
+	return trace.SpanContextFromContext(spanContext(true))
+}
+
+func (s *recordingSpan) AddEvent(name string, opts ...trace.EventOption) {
+	cfg := trace.NewEventConfig(opts...)
+	s.events = append(s.events, spanEvent{name, cfg.Attributes()})
+}
+
+func TestSpanEvents(t *testing.T) {
+	span := &recordingSpan{}
+	ctx := trace.ContextWithSpan(context.Background(), span)
+	logger, buf := newBufferLogger(WithSpanEvents(ErrorLevel))
+	logger.SetLevel(DebugLevel)
+
+	logger.Debug(ctx, `cache miss`, `key`, `users`)
+	logger.Error(ctx, `query failed`, `table`, `users`, `rows`, 2, Error(errors.New(`timeout`)), Any(`tags`, []string{`a`}))
+	logger.Error(context.Background(), `no span`)
+
+	if n := len(decodeLines(t, buf)); n != 3 {
+		t.Errorf("got %d entries, want 3", n)
+	}
+	want := []spanEvent{{`query failed`, []attribute.KeyValue{
+		attribute.String(`level`, `error`),
+		attribute.String(`table`, `users`),
+		attribute.Int64(`rows`, 2),
+		attribute.String(`error`, `timeout`),
+	}}}
+	if !reflect.DeepEqual(span.events, want) {
+		t.Errorf("got events %v, want %v", span.events, want)
+	}
+}
+
+func TestSpanEventsWithoutFields(t *testing.T) {
+	span := &recordingSpan{}
+	ctx := trace.ContextWithSpan(context.Background(), span)
+	logger, _ := newBufferLogger(WithSpanEvents(WarnLevel), WithTraceFieldsMinLevel(ErrorLevel))
+
+	logger.Warn(ctx, `slow`)
+	want := []spanEvent{{`slow`, []attribute.KeyValue{attribute.String(`level`, `warn`)}}}
+	if !reflect.DeepEqual(span.events, want) {
+		t.Errorf("got events %v, want %v", span.events, want)
+	}
+}
+
+func TestSpanEventsDisabled(t *testing.T) {
+	span := &recordingSpan{}
+	logger, _ := newBufferLogger()
+	logger.Error(trace.ContextWithSpan(context.Background(), span), `failed`)
+	if len(span.events) != 0 {
+		t.Errorf("got events %v without WithSpanEvents, want none", span.events)
+	}
+}
@@ -0,0 +1,70 @@
+package log
+
+import (
+	"os"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// stdout is shared by every logger writing to os.Stdout, like stderr.
+var stdout = nopCloserSink{newBrokenPipeSink(zapcore.Lock(consoleSink{os.Stdout}))}
+
+// WithSplitStreams writes entries below ErrorLevel to os.Stdout and entries
+// at ErrorLevel and above to os.Stderr, as many container setups expect. Each
+// entry goes to exactly one of the streams, and the logger's level applies to
+// both.
+func WithSplitStreams() Option {
+	return func(o *options) {
+		o.sink = stdout
+		o.writer = nil
+		o.errSink = stderr
+		o.terminal = isTerminal(os.Stdout)
+	}
+}
+
+// newSplitCore writes the entries enabled by level to out, or to errOut if
+// they're at ErrorLevel or above.
+func newSplitCore(enc zapcore.Encoder, out, errOut zapcore.WriteSyncer, level zapcore.LevelEnabler) zapcore.Core {
+	low := zap.LevelEnablerFunc(func(l Level) bool { return l < zapcore.ErrorLevel && level.Enabled(l) })
+	high := zap.LevelEnablerFunc(func(l Level) bool { return l >= zapcore.ErrorLevel && level.Enabled(l) })
+	return &splitCore{
+		low:  zapcore.NewCore(enc, out, low),
+		high: zapcore.NewCore(enc.Clone(), errOut, high),
+	}
+}
+
+// splitCore routes each entry to low or high by its level. Unlike a tee, it
+// routes in Write too, so the cores wrapping it that write to it directly,
+// such as squelchCore, don't send the entry to both streams.
+type splitCore struct {
+	low, high zapcore.Core
+}
+
+func (c *splitCore) core(level Level) zapcore.Core {
+	if level >= zapcore.ErrorLevel {
+		return c.high
+	}
+	return c.low
+}
+
+func (c *splitCore) Enabled(level Level) bool {
+	return c.core(level).Enabled(level)
+}
+
+func (c *splitCore) With(fields []Field) zapcore.Core {
+	return &splitCore{low: c.low.With(fields), high: c.high.With(fields)}
+}
+
+func (c *splitCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return c.core(ent.Level).Check(ent, ce)
+}
+
+func (c *splitCore) Write(ent zapcore.Entry, fields []Field) error {
+	return c.core(ent.Level).Write(ent, fields)
+}
+
+func (c *splitCore) Sync() error {
+	return multierr.Append(c.low.Sync(), c.high.Sync())
+}
@@ -0,0 +1,141 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWithSplitStreams(t *testing.T) {
+	if o := newOptions(false, []Option{WithSplitStreams()}); o.sink != stdout || o.errSink != stderr {
+		t.Errorf("got sinks %#v and %#v, want stdout and stderr", o.sink, o.errSink)
+	}
+
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	logger := NewLogger(WithSplitStreams(), func(o *options) {
+		o.sink, o.errSink = zapcore.AddSync(out), zapcore.AddSync(errOut)
+	})
+	ctx := context.Background()
+	logger.Debug(ctx, `hidden`)
+	logger.Info(ctx, `info`)
+	logger.Warn(ctx, `warn`)
+	logger.Error(ctx, `error`)
+	logger.SetLevel(ErrorLevel)
+	logger.Warn(ctx, `hidden`)
+
+	for _, tt := range []struct {
+		name string
+		buf  *bytes.Buffer
+		want []string
+	}{
+		{`stdout`, out, []string{`info`, `warn`}},
+		{`stderr`, errOut, []string{`error`}},
+	} {
+		entries := decodeLines(t, tt.buf)
+		if len(entries) != len(tt.want) {
+			t.Errorf("%s: got %d entries, want %d", tt.name, len(entries), len(tt.want))
+			continue
+		}
+		for i, msg := range tt.want {
+			if entries[i][`message`] != msg {
+				t.Errorf("%s: got %v, want %s", tt.name, entries[i], msg)
+			}
+		}
+	}
+}
+
+func TestSplitStreamsWithSquelchRepeats(t *testing.T) {
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	logger := NewLogger(WithSplitStreams(), WithSquelchRepeats(), func(o *options) {
+		o.sink, o.errSink = zapcore.AddSync(out), zapcore.AddSync(errOut)
+	})
+	ctx := context.Background()
+	logger.Info(ctx, `info`)
+	logger.Info(ctx, `info`)
+	logger.Error(ctx, `error`)
+	logger.Error(ctx, `error`)
+	if err := logger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name  string
+		buf   *bytes.Buffer
+		level string
+		want  []string
+	}{
+		{`stdout`, out, `info`, []string{`info`, `last message repeated 1 times`}},
+		{`stderr`, errOut, `error`, []string{`error`, `last message repeated 1 times`}},
+	} {
+		entries := decodeLines(t, tt.buf)
+		if len(entries) != len(tt.want) {
+			t.Errorf("%s: got %d entries, want %d", tt.name, len(entries), len(tt.want))
+			continue
+		}
+		for i, msg := range tt.want {
+			if e := entries[i]; e[`message`] != msg || e[`level`] != tt.level {
+				t.Errorf("%s: got %v, want %s at %s", tt.name, e, msg, tt.level)
+			}
+		}
+	}
+}
+
+func TestSplitStreamsWithCoreOptions(t *testing.T) {
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	hooked := map[Level]int{}
+	logger := NewLogger(
+		WithSplitStreams(),
+		WithSquelchRepeats(),
+		WithSampling(2, 100),
+		WithHook(func(ent zapcore.Entry) error {
+			hooked[ent.Level]++
+			return nil
+		}),
+		func(o *options) {
+			o.sink, o.errSink = zapcore.AddSync(out), zapcore.AddSync(errOut)
+		},
+	)
+	for i := 0; i < 4; i++ {
+		logger.Infow(`flood`)
+	}
+	for i := 0; i < 4; i++ {
+		logger.Errorw(`failed`)
+	}
+	logger.Warnw(`warn`)
+	if err := logger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		buf  *bytes.Buffer
+		want []struct{ level, message string }
+	}{
+		{`stdout`, out, []struct{ level, message string }{
+			{`info`, `flood`},
+			{`info`, `last message repeated 1 times`},
+			{`warn`, `warn`},
+		}},
+		{`stderr`, errOut, []struct{ level, message string }{
+			{`error`, `failed`},
+			{`error`, `last message repeated 1 times`},
+		}},
+	} {
+		entries := decodeLines(t, tt.buf)
+		if len(entries) != len(tt.want) {
+			t.Errorf("%s: got %d entries, want %d", tt.name, len(entries), len(tt.want))
+			continue
+		}
+		for i, w := range tt.want {
+			if e := entries[i]; e[`level`] != w.level || e[`message`] != w.message {
+				t.Errorf("%s: got %v, want %s at %s", tt.name, e, w.message, w.level)
+			}
+		}
+	}
+	if want := map[Level]int{InfoLevel: 2, ErrorLevel: 2, WarnLevel: 1}; !reflect.DeepEqual(hooked, want) {
+		t.Errorf("got hooked entries %v, want the sampled ones %v", hooked, want)
+	}
+}
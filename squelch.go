@@ -0,0 +1,96 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+// WithSquelchRepeats collapses runs of identical consecutive entries, like
+// syslog does: when an entry has the same level, logger name, message and
+// fields as the previous one it isn't written, and a "last message repeated
+// N times" entry is written once a different entry arrives or the logger is
+// synced.
+func WithSquelchRepeats() Option {
+	return func(o *options) {
+		o.squelchRepeats = true
+	}
+}
+
+// squelchState is shared by a squelchCore and the cores derived from it with
+// With.
+type squelchState struct {
+	mu      sync.Mutex
+	core    *squelchCore
+	ent     zapcore.Entry
+	fields  []Field
+	repeats int
+}
+
+type squelchCore struct {
+	zapcore.Core
+	state *squelchState
+}
+
+func newSquelchCore(core zapcore.Core) zapcore.Core {
+	return &squelchCore{Core: core, state: &squelchState{}}
+}
+
+func (c *squelchCore) With(fields []Field) zapcore.Core {
+	return &squelchCore{Core: c.Core.With(fields), state: c.state}
+}
+
+func (c *squelchCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *squelchCore) Write(ent zapcore.Entry, fields []Field) error {
+	s := c.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.core == c && s.same(ent, fields) {
+		s.repeats++
+		s.ent.Time = ent.Time
+		return nil
+	}
+	err := s.flush()
+	s.core, s.ent, s.fields = c, ent, append(s.fields[:0], fields...)
+	return multierr.Append(err, c.Core.Write(ent, fields))
+}
+
+func (c *squelchCore) Sync() error {
+	c.state.mu.Lock()
+	err := c.state.flush()
+	c.state.mu.Unlock()
+	return multierr.Append(err, c.Core.Sync())
+}
+
+func (s *squelchState) same(ent zapcore.Entry, fields []Field) bool {
+	if ent.Level != s.ent.Level || ent.LoggerName != s.ent.LoggerName ||
+		ent.Message != s.ent.Message || len(fields) != len(s.fields) {
+		return false
+	}
+	for i := range fields {
+		if !fields[i].Equals(s.fields[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// flush writes the summary of the squelched repeats, if any.
+func (s *squelchState) flush() error {
+	if s.repeats == 0 {
+		return nil
+	}
+	ent := s.ent
+	ent.Message = fmt.Sprintf(`last message repeated %d times`, s.repeats)
+	ent.Stack = ``
+	s.repeats = 0
+	return s.core.Core.Write(ent, nil)
+}
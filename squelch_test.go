@@ -0,0 +1,36 @@
+package log
+
+import "testing"
+
+func TestSquelchRepeats(t *testing.T) {
+	logger, buf := newBufferLogger(WithSquelchRepeats())
+	for i := 0; i < 4; i++ {
+		logger.Warnw(`disk almost full`, `disk`, `sda`)
+	}
+	logger.Warnw(`disk almost full`, `disk`, `sdb`)
+	logger.Infow(`different`)
+	logger.Infow(`different`)
+	if err := logger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := decodeLines(t, buf)
+	want := []struct{ level, message string }{
+		{`warn`, `disk almost full`},
+		{`warn`, `last message repeated 3 times`},
+		{`warn`, `disk almost full`},
+		{`info`, `different`},
+		{`info`, `last message repeated 1 times`},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, w := range want {
+		if e := entries[i]; e[`level`] != w.level || e[`message`] != w.message {
+			t.Errorf("entry %d: got %v, want %s at %s", i, e, w.message, w.level)
+		}
+	}
+	if entries[2][`disk`] != `sdb` {
+		t.Errorf("got %v, want the entry with different fields written", entries[2])
+	}
+}
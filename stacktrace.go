@@ -0,0 +1,83 @@
+package log
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// WithStacktraceLevel attaches stacktraces to entries at or above level,
+// instead of ErrorLevel, the default of NewLogger, or WarnLevel, the default
+// of NewDevelopmentLogger. E.g. DPanicLevel keeps stacks to the entries that
+// really need them, and InfoLevel helps when debugging.
+func WithStacktraceLevel(level Level) Option {
+	return func(o *options) {
+		o.stacktraceLevel = level
+	}
+}
+
+// defaultStacktraceLevel returns the level from which stacktraces are
+// attached unless WithStacktraceLevel is used.
+func defaultStacktraceLevel(development bool) Level {
+	if development {
+		return zapcore.WarnLevel
+	}
+	return zapcore.ErrorLevel
+}
+
+// WithStacktraceFilter omits the frames for which omit returns true from the
+// stacktraces attached to entries, e.g. frames of vendored middleware, so
+// error stacks focus on the application's own code.
+func WithStacktraceFilter(omit func(frame runtime.Frame) bool) Option {
+	return func(o *options) {
+		o.stackFilter = omit
+	}
+}
+
+// addStack attaches a filtered stacktrace to ce when a stacktrace filter is
+// set; otherwise zap already attached an unfiltered one. It must be called
+// directly by logw or logf.
+func (l *Logger) addStack(ce *zapcore.CheckedEntry) {
+	if l.opts.stackFilter == nil || ce.Level < l.opts.stacktraceLevel {
+		return
+	}
+	// Skip runtime.Callers, addStack, logw or logf, and the logging method
+	// together with the frames covered by the caller skip.
+	ce.Stack = filteredStack(3+l.opts.callerSkip, l.opts.stackFilter)
+}
+
+// filteredStack formats the stack of the calling goroutine like zap does,
+// skipping the first skip frames and the frames matching omit.
+func filteredStack(skip int, omit func(frame runtime.Frame) bool) string {
+	pcs := make([]uintptr, 64)
+	for {
+		n := runtime.Callers(skip, pcs)
+		if n < len(pcs) {
+			pcs = pcs[:n]
+			break
+		}
+		pcs = make([]uintptr, len(pcs)*2)
+	}
+
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != `` && frame.Function != `runtime.goexit` && !omit(frame) {
+			if b.Len() > 0 {
+				b.WriteByte('\n')
+			}
+			b.WriteString(frame.Function)
+			b.WriteString("\n\t")
+			b.WriteString(frame.File)
+			b.WriteByte(':')
+			b.WriteString(strconv.Itoa(frame.Line))
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,66 @@
+package log
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestStacktraceFilter(t *testing.T) {
+	logger, buf := newBufferLogger(WithStacktraceFilter(func(frame runtime.Frame) bool {
+		return strings.HasPrefix(frame.Function, `testing.`)
+	}))
+	logger.Error(context.Background(), `failed`)
+
+	stack, _ := decodeLine(t, buf)[`stacktrace`].(string)
+	if !strings.HasPrefix(stack, `github.com/vsjadeja/log.TestStacktraceFilter`) {
+		t.Errorf("got stacktrace %q, want it to start at the test", stack)
+	}
+	if strings.Contains(stack, `testing.tRunner`) {
+		t.Errorf("got stacktrace %q, want the testing frames omitted", stack)
+	}
+}
+
+func TestStacktraceLevel(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		development bool
+		opts        []Option
+		threshold   Level
+	}{
+		{`production default`, false, nil, ErrorLevel},
+		{`development default`, true, nil, WarnLevel},
+		{`info`, false, []Option{WithStacktraceLevel(InfoLevel)}, InfoLevel},
+		{`dpanic`, false, []Option{WithStacktraceLevel(DPanicLevel)}, DPanicLevel},
+		{`filtered`, false, []Option{WithStacktraceLevel(WarnLevel), WithStacktraceFilter(func(runtime.Frame) bool { return false })}, WarnLevel},
+	} {
+		logger, buf := newBufferLogger(tt.opts...)
+		stackKey, levelKey := `stacktrace`, `level`
+		if tt.development {
+			logger, buf = newDevelopmentBufferLogger(tt.opts...)
+			stackKey, levelKey = `S`, `L`
+		}
+		logger.SetLevel(DebugLevel)
+		for _, lvl := range []Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, DPanicLevel} {
+			// DPanic panics in development.
+			if lvl == DPanicLevel && tt.development {
+				continue
+			}
+			logger.Log(context.Background(), lvl, `boundary`)
+		}
+		entries := decodeLines(t, buf)
+		if len(entries) < 4 {
+			t.Fatalf("%s: got %d entries, want one per level", tt.name, len(entries))
+		}
+		for _, e := range entries {
+			lvl, err := ParseLevel(e[levelKey].(string))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := e[stackKey]; ok != (lvl >= tt.threshold) {
+				t.Errorf("%s: got stacktrace %v at %v, want it from %v", tt.name, ok, lvl, tt.threshold)
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package log
+
+import "go.uber.org/zap/zapcore"
+
+// SugaredLogger logs printf-style messages. It's the supported replacement
+// for the deprecated f methods of Logger: it writes through the logger it
+// was created from, so it shares its output, fields and level, and SetLevel
+// on that logger applies to it. Prefer the structured methods of Logger for
+// new code.
+type SugaredLogger struct {
+	l *Logger
+}
+
+// Sugared returns a SugaredLogger that writes through l.
+func (l *Logger) Sugared() *SugaredLogger {
+	return &SugaredLogger{l: l}
+}
+
+// Logger returns the logger s writes through.
+func (s *SugaredLogger) Logger() *Logger {
+	return s.l
+}
+
+// Debugf uses fmt.Sprintf to log a templated message.
+func (s *SugaredLogger) Debugf(format string, args ...interface{}) {
+	s.l.logf(zapcore.DebugLevel, format, args)
+}
+
+// Infof uses fmt.Sprintf to log a templated message.
+func (s *SugaredLogger) Infof(format string, args ...interface{}) {
+	s.l.logf(zapcore.InfoLevel, format, args)
+}
+
+// Warnf uses fmt.Sprintf to log a templated message.
+func (s *SugaredLogger) Warnf(format string, args ...interface{}) {
+	s.l.logf(zapcore.WarnLevel, format, args)
+}
+
+// Errorf uses fmt.Sprintf to log a templated message.
+func (s *SugaredLogger) Errorf(format string, args ...interface{}) {
+	s.l.logf(zapcore.ErrorLevel, format, args)
+}
+
+// DPanicf uses fmt.Sprintf to log a templated message. In development, the
+// logger then panics. (See DPanicLevel for details.)
+func (s *SugaredLogger) DPanicf(format string, args ...interface{}) {
+	s.l.logf(zapcore.DPanicLevel, format, args)
+}
+
+// Panicf uses fmt.Sprintf to log a templated message, then panics.
+func (s *SugaredLogger) Panicf(format string, args ...interface{}) {
+	s.l.logf(zapcore.PanicLevel, format, args)
+}
+
+// Fatalf uses fmt.Sprintf to log a templated message, then calls os.Exit.
+func (s *SugaredLogger) Fatalf(format string, args ...interface{}) {
+	s.l.logf(zapcore.FatalLevel, format, args)
+}
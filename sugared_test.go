@@ -0,0 +1,42 @@
+package log
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSugared(t *testing.T) {
+	logger, buf := newBufferLogger()
+	s := logger.With(String(`k`, `v`)).Sugared()
+	if s.Logger() == nil {
+		t.Fatal("got a nil Logger")
+	}
+
+	s.Debugf(`hidden %d`, 1)
+	logger.SetLevel(DebugLevel)
+	_, _, line, _ := runtime.Caller(0)
+	s.Debugf(`shown %d`, 2)
+	logger.SetLevel(WarnLevel)
+	s.Infof(`hidden %d`, 3)
+	s.Warnf(`shown %d`, 4)
+	s.Errorf(`shown %s`, `5`)
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	for i, want := range []struct{ level, msg string }{
+		{`debug`, `shown 2`},
+		{`warn`, `shown 4`},
+		{`error`, `shown 5`},
+	} {
+		if e := entries[i]; e[`level`] != want.level || e[`message`] != want.msg || e[`k`] != `v` {
+			t.Errorf("got %v, want %s at %s with the attached field", e, want.msg, want.level)
+		}
+	}
+	if caller, _ := entries[0][`caller`].(string); !strings.HasSuffix(caller, `sugared_test.go:`+strconv.Itoa(line+1)) {
+		t.Errorf("got caller %s, want line %d", caller, line+1)
+	}
+}
@@ -0,0 +1,103 @@
+package log
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/multierr"
+)
+
+// A DanglingKeyError reports the last of an odd number of key-value arguments.
+type DanglingKeyError struct {
+	Position int
+	Key      interface{}
+}
+
+func (e *DanglingKeyError) Error() string {
+	return fmt.Sprintf(`log: key %v at position %d has no value`, e.Key, e.Position)
+}
+
+// A NonStringKeyError reports a key-value pair whose key isn't a string.
+type NonStringKeyError struct {
+	Position   int
+	Key, Value interface{}
+}
+
+func (e *NonStringKeyError) Error() string {
+	return fmt.Sprintf(`log: key %v of type %T at position %d isn't a string`, e.Key, e.Key, e.Position)
+}
+
+// Sweeten converts loosely-typed arguments to fields, exactly like the
+// logging methods do: Fields are kept as they are, a context.Context adds its
+// trace ID if it has one, and every other argument is a key followed by its
+// value. Instead of logging a DPanic entry, Sweeten reports a key without a
+// value as a *DanglingKeyError and each pair with a non-string key as a
+// *NonStringKeyError, combined with multierr. The fields built from the valid
+// arguments are returned in any case.
+func Sweeten(kv ...interface{}) ([]Field, error) {
+	fields, invalids, dangling := sweeten(make([]Field, 0, len(kv)), kv, traceValid)
+	var err error
+	for _, p := range invalids {
+		err = multierr.Append(err, &NonStringKeyError{Position: p.position, Key: p.key, Value: p.value})
+	}
+	if dangling >= 0 {
+		err = multierr.Append(err, &DanglingKeyError{Position: dangling, Key: kv[dangling]})
+	}
+	return fields, err
+}
+
+// traceMode decides which trace IDs the contexts passed to sweeten add.
+type traceMode int
+
+const (
+	// traceNone adds no trace IDs.
+	traceNone traceMode = iota
+	// traceValid adds the trace IDs of valid spans.
+	traceValid
+	// traceSampled adds the trace IDs of sampled spans, see
+	// WithTraceSampling.
+	traceSampled
+)
+
+// sweeten appends the fields built from kv to fields. Contexts add their
+// trace ID as decided by mode. It also returns the pairs with non-string keys
+// and the position of a key without a value, or -1.
+func sweeten(fields []Field, kv []interface{}, mode traceMode) ([]Field, invalidPairs, int) {
+	var invalids invalidPairs
+	for i, n := 0, len(kv); i < n; {
+		if f, ok := kv[i].(Field); ok {
+			fields = append(fields, f)
+			i++
+			continue
+		}
+
+		if ctx, ok := kv[i].(context.Context); ok {
+			i++
+
+			if mode == traceNone || mode == traceSampled && !sampled(ctx) {
+				continue
+			}
+			f := TraceId(ctx)
+			if f.String != NoTraceId {
+				fields = append(fields, f)
+			}
+
+			continue
+		}
+
+		if i == n-1 {
+			return fields, invalids, i
+		}
+		k, v := kv[i], kv[i+1]
+		if s, ok := k.(string); !ok {
+			if cap(invalids) == 0 {
+				invalids = make(invalidPairs, 0, n/2)
+			}
+			invalids = append(invalids, invalidPair{i, k, v})
+		} else {
+			fields = append(fields, Any(s, v))
+		}
+		i += 2
+	}
+	return fields, invalids, -1
+}
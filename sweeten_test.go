@@ -0,0 +1,58 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.uber.org/multierr"
+)
+
+func TestSweeten(t *testing.T) {
+	fields, err := Sweeten(String(`field`, `f`), `key`, `value`, spanContext(true), context.Background(), `n`, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := encodeFields(t, fields...)
+	want := map[string]interface{}{`field`: `f`, `key`: `value`, `traceId`: testTraceID, `n`: 1.0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSweetenErrors(t *testing.T) {
+	fields, err := Sweeten(`key`, `value`, 42, `answer`, `lone`)
+	if got := encodeFields(t, fields...); !reflect.DeepEqual(got, map[string]interface{}{`key`: `value`}) {
+		t.Errorf("got fields %v, want only the valid pair", got)
+	}
+	errs := multierr.Errors(err)
+	if len(errs) != 2 {
+		t.Fatalf("got errors %v, want 2", errs)
+	}
+	var nonString *NonStringKeyError
+	if !errors.As(errs[0], &nonString) || nonString.Position != 2 || nonString.Key != 42 || nonString.Value != `answer` {
+		t.Errorf("got %#v, want a NonStringKeyError at position 2", errs[0])
+	}
+	var dangling *DanglingKeyError
+	if !errors.As(errs[1], &dangling) || dangling.Position != 4 || dangling.Key != `lone` {
+		t.Errorf("got %#v, want a DanglingKeyError at position 4", errs[1])
+	}
+}
+
+func TestSweetenMatchesLogger(t *testing.T) {
+	kv := []interface{}{String(`field`, `f`), `key`, `value`, spanContext(true), `n`, 1}
+	logger, buf := newBufferLogger()
+	logger.Infow(`sweetened`, kv...)
+	fields, err := Sweeten(kv...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logged := decodeLine(t, buf)
+	for key, value := range encodeFields(t, fields...) {
+		if logged[key] != value {
+			t.Errorf("got %s=%v from the logger, want %v as from Sweeten", key, logged[key], value)
+		}
+	}
+}
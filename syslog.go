@@ -0,0 +1,182 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// facilityUser is the syslog facility of the messages, user-level messages.
+const facilityUser = 1
+
+// syslogSockets are tried in order to reach the local syslog daemon.
+var syslogSockets = []string{`/dev/log`, `/var/run/syslog`, `/var/run/log`}
+
+// WithSyslog makes the logger send entries to a syslog server, framed as
+// RFC 5424 messages with the given tag as the app name. The priority is
+// computed from the entry level, and the entry itself, encoded in the
+// logger's format, is the message. network and addr are passed to net.Dial,
+// e.g. "udp" and "localhost:514"; empty ones connect to the local syslog
+// daemon. The connection is made on the first entry and made again if a
+// write fails. Over stream networks like "tcp" messages are framed by octet
+// counting (RFC 6587).
+func WithSyslog(network, addr, tag string) Option {
+	return func(o *options) {
+		o.sink = newSyslogWriter(network, addr)
+		o.writer = nil
+		o.errSink = nil
+		o.syslogTag = tag
+	}
+}
+
+// syslogWriter sends every Write as one syslog message.
+type syslogWriter struct {
+	network, addr string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newSyslogWriter(network, addr string) *syslogWriter {
+	return &syslogWriter{network: network, addr: addr}
+}
+
+func (w *syslogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn != nil {
+		if err := w.send(p); err == nil {
+			return len(p), nil
+		}
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	if err := w.dial(); err != nil {
+		return 0, err
+	}
+	if err := w.send(p); err != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+		return 0, fmt.Errorf(`log: can't write to syslog: %w`, err)
+	}
+	return len(p), nil
+}
+
+// send writes the message p, framed by octet counting on stream networks.
+func (w *syslogWriter) send(p []byte) error {
+	if w.stream() {
+		if _, err := w.conn.Write(append(strconv.AppendInt(nil, int64(len(p)), 10), ' ')); err != nil {
+			return err
+		}
+	}
+	_, err := w.conn.Write(p)
+	return err
+}
+
+func (w *syslogWriter) stream() bool {
+	switch w.conn.LocalAddr().Network() {
+	case `tcp`, `tcp4`, `tcp6`, `unix`:
+		return true
+	}
+	return false
+}
+
+func (w *syslogWriter) dial() error {
+	if w.network != `` || w.addr != `` {
+		conn, err := net.Dial(w.network, w.addr)
+		if err != nil {
+			return fmt.Errorf(`log: can't connect to syslog: %w`, err)
+		}
+		w.conn = conn
+		return nil
+	}
+	for _, path := range syslogSockets {
+		for _, network := range []string{`unixgram`, `unix`} {
+			if conn, err := net.Dial(network, path); err == nil {
+				w.conn = conn
+				return nil
+			}
+		}
+	}
+	return errors.New(`log: can't connect to the local syslog daemon`)
+}
+
+// Sync does nothing, messages are sent unbuffered.
+func (w *syslogWriter) Sync() error {
+	return nil
+}
+
+func (w *syslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// syslogCore frames entries as RFC 5424 messages, which needs the level, so
+// it can't be done by the writer.
+type syslogCore struct {
+	zapcore.LevelEnabler
+	enc  zapcore.Encoder
+	out  *syslogWriter
+	tag  string
+	host string
+	pid  int
+}
+
+func newSyslogCore(enc zapcore.Encoder, out *syslogWriter, tag string, level zapcore.LevelEnabler) zapcore.Core {
+	host, err := os.Hostname()
+	if err != nil || host == `` {
+		host = `-`
+	}
+	if tag == `` {
+		tag = `-`
+	}
+	return &syslogCore{LevelEnabler: level, enc: enc, out: out, tag: tag, host: host, pid: os.Getpid()}
+}
+
+func (c *syslogCore) With(fields []Field) zapcore.Core {
+	clone := *c
+	clone.enc = c.enc.Clone()
+	for i := range fields {
+		fields[i].AddTo(clone.enc)
+	}
+	return &clone
+}
+
+func (c *syslogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *syslogCore) Write(ent zapcore.Entry, fields []Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+	// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+	msg := []byte(fmt.Sprintf(`<%d>1 %s %s %s %d - - `,
+		facilityUser*8+syslogSeverity(ent.Level),
+		ent.Time.Format(time.RFC3339Nano), c.host, c.tag, c.pid))
+	msg = append(msg, bytes.TrimRight(buf.Bytes(), "\r\n")...)
+	_, err = c.out.Write(msg)
+	return err
+}
+
+func (c *syslogCore) Sync() error {
+	return c.out.Sync()
+}
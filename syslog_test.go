@@ -0,0 +1,104 @@
+package log
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// syslogHeader returns the RFC 5424 header the logger sends for an entry at
+// the test clock's time.
+func syslogHeader(pri int, tag string) string {
+	host, err := os.Hostname()
+	if err != nil || host == `` {
+		host = `-`
+	}
+	return fmt.Sprintf(`<%d>1 2020-01-01T00:00:00Z %s %s %d - - `, pri, host, tag, os.Getpid())
+}
+
+func TestSyslogUDP(t *testing.T) {
+	conn, err := net.ListenPacket(`udp`, `127.0.0.1:0`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	logger := NewLogger(WithSyslog(`udp`, conn.LocalAddr().String(), `app`), WithClock(newTestClock()), WithCaller(false))
+	defer logger.Close()
+
+	logger.Warnw(`disk almost full`, `percent`, 91)
+	logger.Errorw(`disk full`)
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for _, tt := range []struct {
+		pri int
+		msg string
+	}{
+		{12, `disk almost full`},
+		{11, `disk full`},
+	} {
+		p := make([]byte, 64*1024)
+		n, _, err := conn.ReadFrom(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		header := syslogHeader(tt.pri, `app`)
+		msg := string(p[:n])
+		if !strings.HasPrefix(msg, header) {
+			t.Fatalf("got %q, want the header %q", msg, header)
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(msg, header)), &entry); err != nil {
+			t.Fatalf("got %q, want a JSON entry after the header: %v", msg, err)
+		}
+		if entry[`message`] != tt.msg {
+			t.Errorf("got %v, want %s", entry, tt.msg)
+		}
+	}
+}
+
+func TestSyslogTCP(t *testing.T) {
+	ln, err := net.Listen(`tcp`, `127.0.0.1:0`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	logger := NewLogger(WithSyslog(`tcp`, ln.Addr().String(), ``), WithClock(newTestClock()), WithFormat(formatLogfmt), WithCaller(false))
+	defer logger.Close()
+
+	logger.Infow(`first`)
+	logger.Infow(`second`)
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	r := bufio.NewReader(conn)
+	for _, msg := range []string{`first`, `second`} {
+		// Messages are framed by octet counting.
+		length, err := r.ReadString(' ')
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(length, ` `))
+		if err != nil {
+			t.Fatalf("got length %q: %v", length, err)
+		}
+		p := make([]byte, n)
+		if _, err := io.ReadFull(r, p); err != nil {
+			t.Fatal(err)
+		}
+		want := syslogHeader(14, `-`) + `time=2020-01-01T00:00:00.000Z level=info message=` + msg
+		if string(p) != want {
+			t.Errorf("got %q, want %q", p, want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package log
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// WithTee also writes entries to cores, each with its own level, encoder and
+// output, e.g. warnings and above to a file for operators while the logger
+// writes everything to os.Stderr:
+//
+//	file := zapcore.NewCore(zapcore.NewJSONEncoder(cfg), zapcore.AddSync(f), log.WarnLevel)
+//	logger := log.NewLogger(log.WithTee(file))
+//
+// The logger's level gates every core: an entry reaches a core only if both
+// the logger's level and the core's own level enable it, so SetLevel acts as
+// a global floor. The option can be given more than once.
+func WithTee(cores ...zapcore.Core) Option {
+	return func(o *options) {
+		o.tee = append(o.tee, cores...)
+	}
+}
+
+// gatedCore applies the logger's level on top of the level of a teed core.
+type gatedCore struct {
+	zapcore.Core
+	level zapcore.LevelEnabler
+}
+
+func (c *gatedCore) Enabled(level Level) bool {
+	return c.level.Enabled(level) && c.Core.Enabled(level)
+}
+
+func (c *gatedCore) With(fields []Field) zapcore.Core {
+	return &gatedCore{Core: c.Core.With(fields), level: c.level}
+}
+
+func (c *gatedCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.level.Enabled(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
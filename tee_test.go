@@ -0,0 +1,111 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWithTee(t *testing.T) {
+	debugBuf, warnBuf := &bytes.Buffer{}, &bytes.Buffer{}
+	enc := zapcore.NewJSONEncoder(productionEncoderConfig)
+	logger, buf := newBufferLogger(WithTee(
+		zapcore.NewCore(enc, zapcore.AddSync(debugBuf), DebugLevel),
+		zapcore.NewCore(enc.Clone(), zapcore.AddSync(warnBuf), WarnLevel),
+	))
+	logger.SetLevel(DebugLevel)
+	ctx := context.Background()
+	logger.Debug(ctx, `debug`)
+	logger.Info(ctx, `info`)
+	logger.Warn(ctx, `warn`)
+	logger.With(String(`k`, `v`)).Error(ctx, `error`)
+
+	for _, tt := range []struct {
+		name string
+		buf  *bytes.Buffer
+		want []string
+	}{
+		{`logger`, buf, []string{`debug`, `info`, `warn`, `error`}},
+		{`debug core`, debugBuf, []string{`debug`, `info`, `warn`, `error`}},
+		{`warn core`, warnBuf, []string{`warn`, `error`}},
+	} {
+		entries := decodeLines(t, tt.buf)
+		if len(entries) != len(tt.want) {
+			t.Errorf("%s: got %d entries, want %d", tt.name, len(entries), len(tt.want))
+			continue
+		}
+		for i, msg := range tt.want {
+			if entries[i][`message`] != msg {
+				t.Errorf("%s: got %v, want %s", tt.name, entries[i], msg)
+			}
+		}
+		if last := entries[len(entries)-1]; last[`k`] != `v` {
+			t.Errorf("%s: got %v, want the attached field", tt.name, last)
+		}
+	}
+
+	// The logger's level is a floor for every core.
+	debugBuf.Reset()
+	warnBuf.Reset()
+	logger.SetLevel(ErrorLevel)
+	logger.Warn(ctx, `hidden`)
+	if debugBuf.Len() != 0 || warnBuf.Len() != 0 {
+		t.Errorf("got %q and %q below the logger's level, want nothing", debugBuf, warnBuf)
+	}
+}
+
+func TestWithTeeWithoutWriter(t *testing.T) {
+	teeBuf := &bytes.Buffer{}
+	enc := zapcore.NewJSONEncoder(productionEncoderConfig)
+	logger := NewLogger(WithWriter(nil), WithTee(zapcore.NewCore(enc, zapcore.AddSync(teeBuf), DebugLevel)))
+	if logger.opts.sink != nil {
+		t.Fatalf("got sink %#v, want none", logger.opts.sink)
+	}
+	logger.Debug(context.Background(), `hidden`)
+	logger.Info(context.Background(), `teed`)
+
+	if e := decodeLine(t, teeBuf); e[`message`] != `teed` {
+		t.Errorf("got %v, want only the info entry teed", e)
+	}
+	if logger.Enabled(DebugLevel) || !logger.Enabled(InfoLevel) {
+		t.Error("got the levels of the teed core, want the logger's level")
+	}
+}
+
+// keepingCore keeps the fields passed to Write without copying them.
+type keepingCore struct {
+	zapcore.LevelEnabler
+	written [][]Field
+}
+
+func (c *keepingCore) With([]Field) zapcore.Core { return c }
+
+func (c *keepingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
+func (c *keepingCore) Write(_ zapcore.Entry, fields []Field) error {
+	c.written = append(c.written, fields)
+	return nil
+}
+
+func (c *keepingCore) Sync() error { return nil }
+
+func TestWithTeeKeepsFields(t *testing.T) {
+	core := &keepingCore{LevelEnabler: DebugLevel}
+	logger, _ := newBufferLogger(WithTee(core))
+	logger.Infow(`first`, `n`, 1)
+	logger.Infow(`second`, `n`, 2)
+	logger.Infos(context.Background(), []Entry{{Msg: `third`, Fields: []Field{Int(`n`, 3)}}, {Msg: `fourth`, Fields: []Field{Int(`n`, 4)}}})
+
+	if len(core.written) != 4 {
+		t.Fatalf("got %d entries, want 4", len(core.written))
+	}
+	for i, fields := range core.written {
+		if len(fields) == 0 || fields[0].Key != `n` || fields[0].Integer != int64(i+1) {
+			t.Errorf("entry %d: got fields %v, want n=%d", i, fields, i+1)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package log
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// transform applies the field transforms enabled by the options to the
+// fields of an entry or of a With call.
+func (o *options) transform(fields []Field) []Field {
+	if o.omitEmpty {
+		fields = omitEmpty(fields)
+	}
+	if len(o.redactedKeys) > 0 {
+		fields = o.redact(fields)
+	}
+	return fields
+}
+
+// redactedValue replaces the values of redacted fields.
+const redactedValue = `[REDACTED]`
+
+// WithRedactedKeys masks the value of every field whose key matches one of
+// keys, ignoring case, e.g. "password" or "token". The value is replaced by
+// "[REDACTED]" whatever its type, for fields added with With as well as the
+// ones passed to the logging calls.
+func WithRedactedKeys(keys ...string) Option {
+	return func(o *options) {
+		if o.redactedKeys == nil {
+			o.redactedKeys = make(map[string]struct{}, len(keys))
+		}
+		for _, key := range keys {
+			o.redactedKeys[strings.ToLower(key)] = struct{}{}
+		}
+	}
+}
+
+// redact returns fields with the values of redacted keys masked. The input
+// slice is only copied when something has to be masked.
+func (o *options) redact(fields []Field) []Field {
+	var out []Field
+	for i, f := range fields {
+		if _, ok := o.redactedKeys[strings.ToLower(f.Key)]; !ok {
+			continue
+		}
+		if out == nil {
+			out = append(make([]Field, 0, len(fields)), fields...)
+		}
+		out[i] = zap.String(f.Key, redactedValue)
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// WithMaxFields caps the number of fields encoded per entry, counting fields
+// added with With as well as the ones passed to the logging call. Extra
+// fields are dropped and every entry gets a single fields_truncated field
+// holding the number of fields dropped from it, including the ones dropped
+// from With calls. Zero or a negative n means no limit.
+func WithMaxFields(n int) Option {
+	return func(o *options) {
+		o.maxFields = n
+	}
+}
+
+// limit truncates fields so that, together with the used fields already
+// attached to the logger, they fit in the configured maximum. It returns the
+// fields kept and the number of fields dropped.
+func (o *options) limit(fields []Field, used int) ([]Field, int) {
+	if o.maxFields <= 0 || used+len(fields) <= o.maxFields {
+		return fields, 0
+	}
+	keep := o.maxFields - used
+	if keep < 0 {
+		keep = 0
+	}
+	return fields[:keep:keep], len(fields) - keep
+}
+
+// limit truncates the fields of an entry like options.limit. The fields it
+// drops and the ones dropped by With on the way to l are counted by a single
+// fields_truncated field.
+func (l *Logger) limit(fields []Field) []Field {
+	fields, dropped := l.opts.limit(fields, len(l.with))
+	if dropped += l.truncated; dropped == 0 {
+		return fields
+	}
+	return append(fields[:len(fields):len(fields)], zap.Int(`fields_truncated`, dropped))
+}
+
+// omitEmpty returns fields without the empty ones. The input slice is only
+// copied when something has to be dropped.
+func omitEmpty(fields []Field) []Field {
+	for i := range fields {
+		if !isEmpty(fields[i]) {
+			continue
+		}
+		out := append(make([]Field, 0, len(fields)-1), fields[:i]...)
+		for _, f := range fields[i+1:] {
+			if !isEmpty(f) {
+				out = append(out, f)
+			}
+		}
+		return out
+	}
+	return fields
+}
+
+// isEmpty reports whether f carries an empty string, a zero number or
+// duration, an empty byte slice or a nil reflected value.
+func isEmpty(f Field) bool {
+	switch f.Type {
+	case zapcore.StringType:
+		return f.String == ``
+	case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type,
+		zapcore.Uint64Type, zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type, zapcore.UintptrType,
+		zapcore.Float64Type, zapcore.Float32Type, zapcore.DurationType:
+		return f.Integer == 0
+	case zapcore.BinaryType, zapcore.ByteStringType:
+		b, _ := f.Interface.([]byte)
+		return len(b) == 0
+	case zapcore.ReflectType:
+		return f.Interface == nil
+	}
+	return false
+}
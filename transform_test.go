@@ -0,0 +1,92 @@
+package log
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestOmitEmpty(t *testing.T) {
+	logger, buf := newBufferLogger(WithOmitEmpty())
+	logger.With(String(`attached`, ``), Int(`attempt`, 1)).Info(context.Background(), `omit`,
+		`name`, ``, `count`, 0, `elapsed`, time.Duration(0), `payload`, []byte{}, `value`, nil,
+		`user`, `alice`, `total`, 3, `ok`, false)
+
+	entry := decodeLine(t, buf)
+	for _, key := range []string{`attached`, `name`, `count`, `elapsed`, `payload`, `value`} {
+		if v, ok := entry[key]; ok {
+			t.Errorf("got %s=%v, want it omitted", key, v)
+		}
+	}
+	for key, want := range map[string]interface{}{`attempt`: 1.0, `user`: `alice`, `total`: 3.0, `ok`: false} {
+		if got := entry[key]; got != want {
+			t.Errorf("got %s=%v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestOmitEmptyDisabled(t *testing.T) {
+	logger, buf := newBufferLogger()
+	logger.Info(context.Background(), `keep`, `name`, ``, `count`, 0)
+
+	entry := decodeLine(t, buf)
+	if entry[`name`] != `` || entry[`count`] != 0.0 {
+		t.Errorf("got %v, want the empty fields kept", entry)
+	}
+}
+
+func TestMaxFields(t *testing.T) {
+	logger, buf := newBufferLogger(WithMaxFields(3))
+	// Infow is used since the context-aware methods add a trace field.
+	logger.Infow(`under`, `a`, 1, `b`, 2)
+	logger.Infow(`over`, `a`, 1, `b`, 2, `c`, 3, `d`, 4, `e`, 5)
+	// Two fields are dropped by With and one more by the call.
+	child := logger.With(Int(`w1`, 1), Int(`w2`, 2), Int(`w3`, 3), Int(`w4`, 4), Int(`w5`, 5))
+	child.Infow(`attached`, `a`, 1)
+	child.Named(`rebuilt`).Infow(`attached`)
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 4 {
+		t.Fatalf("got %d entries, want 4", len(entries))
+	}
+	for i, want := range []struct {
+		keys      []string
+		truncated interface{}
+	}{
+		{[]string{`a`, `b`}, nil},
+		{[]string{`a`, `b`, `c`}, 2.0},
+		{[]string{`w1`, `w2`, `w3`}, 3.0},
+		{[]string{`w1`, `w2`, `w3`}, 2.0},
+	} {
+		e := entries[i]
+		if e[`fields_truncated`] != want.truncated {
+			t.Errorf("entry %d: got fields_truncated %v, want %v", i, e[`fields_truncated`], want.truncated)
+		}
+		for _, key := range want.keys {
+			if _, ok := e[key]; !ok {
+				t.Errorf("entry %d: got no %s field, want it kept", i, key)
+			}
+		}
+		for _, key := range []string{`d`, `e`, `w4`, `w5`} {
+			if _, ok := e[key]; ok {
+				t.Errorf("entry %d: got a %s field, want it dropped", i, key)
+			}
+		}
+	}
+}
+
+func TestRedactedKeys(t *testing.T) {
+	logger, buf := newBufferLogger(WithRedactedKeys(`password`, `Token`))
+	logger.With(String(`TOKEN`, `abc`), String(`user`, `alice`)).Info(context.Background(), `login`,
+		`password`, 1234, `Password`, []string{`a`}, `attempt`, 2)
+
+	e := decodeLine(t, buf)
+	for _, key := range []string{`TOKEN`, `password`, `Password`} {
+		if e[key] != redactedValue {
+			t.Errorf("got %s=%v, want it redacted", key, e[key])
+		}
+	}
+	if e[`user`] != `alice` || e[`attempt`] != 2.0 || e[`message`] != `login` {
+		t.Errorf("got %v, want the other fields untouched", e)
+	}
+}
@@ -0,0 +1,156 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	webhookQueueSize = 64
+	webhookTimeout   = 5 * time.Second
+)
+
+type webhookConfig struct {
+	url      string
+	minLevel Level
+	throttle time.Duration
+}
+
+// WithErrorWebhook POSTs every entry at or above minLevel as a JSON document
+// to url, e.g. a Slack or PagerDuty incoming webhook. At most one entry is
+// sent per throttle interval. Requests are made by a background goroutine
+// from a bounded queue, so logging never waits on the network; entries that
+// are throttled or don't fit in the queue are dropped and their count is
+// reported in the `dropped` field of the next payload. Like the cores given
+// to WithTee, the webhook is gated by the logger's level too.
+func WithErrorWebhook(url string, minLevel Level, throttle time.Duration) Option {
+	return func(o *options) {
+		o.webhook = &webhookConfig{url: url, minLevel: minLevel, throttle: throttle}
+	}
+}
+
+type webhook struct {
+	webhookConfig
+	client  *http.Client
+	queue   chan []byte
+	done    chan struct{}
+	dropped uint64
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+func newWebhook(cfg webhookConfig) *webhook {
+	w := &webhook{
+		webhookConfig: cfg,
+		client:        &http.Client{Timeout: webhookTimeout},
+		queue:         make(chan []byte, webhookQueueSize),
+		done:          make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *webhook) run() {
+	for {
+		select {
+		case payload := <-w.queue:
+			w.post(payload)
+		case <-w.done:
+			return
+		}
+	}
+}
+
+func (w *webhook) post(payload []byte) {
+	resp, err := w.client.Post(w.url, `application/json`, bytes.NewReader(payload))
+	if err != nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
+
+// stop ends the background goroutine. Queued entries are dropped.
+func (w *webhook) stop() {
+	close(w.done)
+}
+
+// allow reports whether an entry logged at t is outside the throttle window
+// of the previously sent one.
+func (w *webhook) allow(t time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.last.IsZero() && t.Sub(w.last) < w.throttle {
+		return false
+	}
+	w.last = t
+	return true
+}
+
+func (w *webhook) enqueue(payload []byte) {
+	select {
+	case w.queue <- payload:
+	default:
+		atomic.AddUint64(&w.dropped, 1)
+	}
+}
+
+type webhookCore struct {
+	*webhook
+	enc zapcore.Encoder
+}
+
+func newWebhookCore(cfg webhookConfig) zapcore.Core {
+	return &webhookCore{
+		webhook: newWebhook(cfg),
+		enc:     zapcore.NewJSONEncoder(encoderConfig(productionEncoderConfig)),
+	}
+}
+
+func (c *webhookCore) Enabled(level Level) bool {
+	return level >= c.minLevel
+}
+
+func (c *webhookCore) With(fields []Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for i := range fields {
+		fields[i].AddTo(enc)
+	}
+	return &webhookCore{webhook: c.webhook, enc: enc}
+}
+
+func (c *webhookCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *webhookCore) Write(ent zapcore.Entry, fields []Field) error {
+	if !c.allow(ent.Time) {
+		atomic.AddUint64(&c.dropped, 1)
+		return nil
+	}
+	if dropped := atomic.SwapUint64(&c.dropped, 0); dropped > 0 {
+		fields = append(fields[:len(fields):len(fields)], zap.Uint64(`dropped`, dropped))
+	}
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	c.enqueue(append([]byte(nil), buf.Bytes()...))
+	buf.Free()
+	return nil
+}
+
+func (c *webhookCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,98 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestErrorWebhook(t *testing.T) {
+	payloads := make(chan map[string]interface{}, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("got an invalid payload: %v", err)
+		}
+		payloads <- payload
+	}))
+	defer srv.Close()
+
+	clock := newTestClock()
+	logger := NewLogger(WithWriter(io.Discard), WithClock(clock), WithErrorWebhook(srv.URL, ErrorLevel, time.Minute))
+	defer logger.Close()
+	ctx := context.Background()
+
+	logger.Error(ctx, `first`, `order`, 1)
+	logger.Warn(ctx, `below the level`)
+	logger.Error(ctx, `throttled`)
+	clock.Add(time.Minute)
+	logger.Error(ctx, `second`)
+
+	for _, want := range []struct {
+		message string
+		dropped interface{}
+	}{
+		{`first`, nil},
+		{`second`, 1.0},
+	} {
+		select {
+		case payload := <-payloads:
+			if payload[`message`] != want.message || payload[`dropped`] != want.dropped {
+				t.Errorf("got payload %v, want message %s and dropped %v", payload, want.message, want.dropped)
+			}
+			if want.message == `first` && payload[`order`] != 1.0 {
+				t.Errorf("got payload %v, want order 1", payload)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for the %s payload", want.message)
+		}
+	}
+	select {
+	case payload := <-payloads:
+		t.Errorf("got an unexpected payload %v", payload)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestErrorWebhookFollowsLevel(t *testing.T) {
+	payloads := make(chan map[string]interface{}, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("got an invalid payload: %v", err)
+		}
+		payloads <- payload
+	}))
+	defer srv.Close()
+
+	clock := newTestClock()
+	logger := NewLogger(WithWriter(io.Discard), WithClock(clock), WithErrorWebhook(srv.URL, ErrorLevel, time.Minute))
+	defer logger.Close()
+	logger.SetLevel(FatalLevel)
+	if logger.Enabled(ErrorLevel) {
+		t.Error("got error enabled after SetLevel(FatalLevel), want it disabled")
+	}
+
+	logger.Error(context.Background(), `silenced`)
+	logger.SetLevel(InfoLevel)
+	clock.Add(time.Minute)
+	logger.Error(context.Background(), `reported`)
+
+	select {
+	case payload := <-payloads:
+		if payload[`message`] != `reported` {
+			t.Errorf("got payload %v, want only the entry logged at the error level", payload)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the payload")
+	}
+	select {
+	case payload := <-payloads:
+		t.Errorf("got an unexpected payload %v", payload)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,128 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxLineLength is the longest line a lineWriter buffers. Longer lines are
+// logged in chunks of this size.
+const maxLineLength = 64 << 10
+
+// CmdWriters returns writers for the Stdout and Stderr of an exec.Cmd that log
+// every line the command writes: stdout at info level and stderr at error
+// level, with the trace fields of ctx. Close the writers once the command has
+// exited to log a final line that doesn't end in a newline. The entries have
+// no caller, since the lines are written from the goroutines copying the
+// command's output rather than from a call site of interest.
+//
+// With WithCancellationLogging, a warning is logged if ctx is canceled before
+// both writers are closed.
+func (l *Logger) CmdWriters(ctx context.Context) (stdout, stderr io.WriteCloser) {
+	stop, open := l.watchCancel(ctx), int32(2)
+	onClose := func() {
+		if atomic.AddInt32(&open, -1) == 0 {
+			stop()
+		}
+	}
+	l = l.WithOptions(zap.WithCaller(false))
+	return newLineWriter(l, ctx, zapcore.InfoLevel, onClose), newLineWriter(l, ctx, zapcore.ErrorLevel, onClose)
+}
+
+// lineWriter logs each line written to it as one entry.
+type lineWriter struct {
+	l     *Logger
+	ctx   context.Context
+	level zapcore.Level
+
+	mu      sync.Mutex
+	buf     []byte
+	onClose func()
+}
+
+func newLineWriter(l *Logger, ctx context.Context, level zapcore.Level, onClose func()) *lineWriter {
+	return &lineWriter{l: l, ctx: ctx, level: level, onClose: onClose}
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.buf = append(w.buf, p...)
+			for len(w.buf) >= maxLineLength {
+				w.log(w.buf[:maxLineLength])
+				w.buf = append(w.buf[:0], w.buf[maxLineLength:]...)
+			}
+			break
+		}
+		w.buf = append(w.buf, p[:i]...)
+		w.flush()
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// Close logs the buffered partial line, if any.
+func (w *lineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		w.flush()
+	}
+	if w.onClose != nil {
+		w.onClose()
+		w.onClose = nil
+	}
+	return nil
+}
+
+// flush logs the buffered line in chunks of at most maxLineLength.
+func (w *lineWriter) flush() {
+	line := bytes.TrimSuffix(w.buf, []byte{'\r'})
+	for len(line) > maxLineLength {
+		w.log(line[:maxLineLength])
+		line = line[maxLineLength:]
+	}
+	w.log(line)
+	w.buf = w.buf[:0]
+}
+
+func (w *lineWriter) log(line []byte) {
+	w.l.logw(w.ctx, w.level, string(line), nil)
+}
+
+// Writer returns a writer that logs every Write call as one entry at the
+// given level, with the written bytes minus a trailing newline as the
+// message. It bridges APIs that take an io.Writer or a standard library
+// *log.Logger, such as http.Server.ErrorLog:
+//
+//	stdlog.New(logger.Writer(log.ErrorLevel), ``, 0)
+//
+// The caller is the one of the *log.Logger method that wrote the entry,
+// such as Printf, so writing to it directly reports a caller two frames up.
+func (l *Logger) Writer(level Level) io.Writer {
+	return levelWriter{l: l.WithOptions(zap.AddCallerSkip(stdLogDepth)), level: level}
+}
+
+// stdLogDepth is the number of frames of a *log.Logger between its caller
+// and Write, e.g. Printf and output.
+const stdLogDepth = 2
+
+type levelWriter struct {
+	l     *Logger
+	level zapcore.Level
+}
+
+func (w levelWriter) Write(p []byte) (int, error) {
+	w.l.logw(nil, w.level, string(bytes.TrimSuffix(p, []byte{'\n'})), nil)
+	return len(p), nil
+}
@@ -0,0 +1,76 @@
+package log
+
+import (
+	"io"
+	stdlog "log"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCmdWriters(t *testing.T) {
+	logger, buf := newBufferLogger()
+	stdout, stderr := logger.CmdWriters(spanContext(true))
+
+	long := strings.Repeat(`x`, maxLineLength+10)
+	io.WriteString(stdout, "one\ntw")
+	io.WriteString(stdout, "o\r\n"+long+"\nthree")
+	io.WriteString(stderr, "oops\n")
+	stdout.Close()
+	stderr.Close()
+
+	entries := decodeLines(t, buf)
+	want := []struct{ level, message string }{
+		{`info`, `one`},
+		{`info`, `two`},
+		{`info`, long[:maxLineLength]},
+		{`info`, long[maxLineLength:]},
+		{`error`, `oops`},
+		{`info`, `three`},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e[`level`] != w.level || e[`message`] != w.message || e[`traceId`] != testTraceID {
+			t.Errorf("entry %d: got %.100v, want %.20q at %s with the trace ID", i, e, w.message, w.level)
+		}
+		if caller, ok := e[`caller`]; ok {
+			t.Errorf("entry %d: got caller %v, want none", i, caller)
+		}
+	}
+}
+
+func TestWriter(t *testing.T) {
+	logger, buf := newBufferLogger()
+	w := logger.Writer(WarnLevel)
+	io.WriteString(w, "first\n")
+	io.WriteString(w, "second")
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	for i, msg := range []string{`first`, `second`} {
+		if e := entries[i]; e[`level`] != `warn` || e[`message`] != msg {
+			t.Errorf("got %v, want %s at warn", e, msg)
+		}
+	}
+}
+
+func TestWriterCaller(t *testing.T) {
+	logger, buf := newBufferLogger()
+	std := stdlog.New(logger.Writer(ErrorLevel), ``, 0)
+	_, _, line, _ := runtime.Caller(0)
+	std.Printf(`failed %d times`, 3)
+
+	e := decodeLine(t, buf)
+	if caller, _ := e[`caller`].(string); !strings.HasSuffix(caller, `writer_test.go:`+strconv.Itoa(line+1)) {
+		t.Errorf("got caller %v, want the Printf call site", e[`caller`])
+	}
+	if e[`message`] != `failed 3 times` || e[`level`] != `error` {
+		t.Errorf("got %v, want the printed line at error", e)
+	}
+}